@@ -131,6 +131,10 @@ func (s FormSerializer) DefaultContentType(withCharset bool) string {
 	return "application/x-www-form-urlencoded"
 }
 
+func (s FormSerializer) CanSerialize() bool {
+	return !s.OnlyDeserialize
+}
+
 func (s FormSerializer) Serialize(v interface{}) ([]byte, error) {
 	if s.OnlyDeserialize {
 		return nil, ErrSerializeOperationsDisabled
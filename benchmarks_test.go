@@ -3,9 +3,13 @@ package just
 import (
 	"fmt"
 	"net/http"
+	"regexp"
 	"testing"
 )
 
+// Number of routes in the benchmarks of routing with many routes.
+const benchmarkNumRoutes = 300
+
 func BenchmarkOneRoute(B *testing.B) {
 	app := New()
 	app.GET("/ping", func(c *Context) IResponse { return &Response{Status: 200} })
@@ -127,6 +131,42 @@ func Benchmark404Many(B *testing.B) {
 	runRequest("Benchmark404Many", B, app, "GET", "/viewfake")
 }
 
+func BenchmarkManyStaticRoutes(B *testing.B) {
+	app := New()
+	for i := 0; i < benchmarkNumRoutes; i++ {
+		app.GET(fmt.Sprintf("/resource%d/list", i), func(c *Context) IResponse { return &Response{Status: 200} })
+	}
+	runRequest("BenchmarkManyStaticRoutes", B, app, "GET", fmt.Sprintf("/resource%d/list", benchmarkNumRoutes-1))
+}
+
+func BenchmarkManyParamRoutes(B *testing.B) {
+	app := New()
+	for i := 0; i < benchmarkNumRoutes; i++ {
+		app.GET(fmt.Sprintf("/resource%d/{id:integer}", i), func(c *Context) IResponse { return &Response{Status: 200} })
+	}
+	runRequest("BenchmarkManyParamRoutes", B, app, "GET", fmt.Sprintf("/resource%d/12345", benchmarkNumRoutes-1))
+}
+
+// Linear scanning of the regular expressions of routes (routing before the tree), for comparison with BenchmarkManyParamRoutes.
+func BenchmarkManyParamRoutesRegexpScan(B *testing.B) {
+	routes := make([]*regexp.Regexp, benchmarkNumRoutes)
+	for i := range routes {
+		routes[i] = regexp.MustCompile(fmt.Sprintf("^/resource%d/%s(\\/)?$", i, patternParamInteger))
+	}
+	path := fmt.Sprintf("/resource%d/12345", benchmarkNumRoutes-1)
+	fmt.Println("BenchmarkManyParamRoutesRegexpScan:")
+	B.ReportAllocs()
+	B.ResetTimer()
+	for i := 0; i < B.N; i++ {
+		for _, rx := range routes {
+			if rx.MatchString(path) {
+				rx.FindStringSubmatchIndex(path)
+				break
+			}
+		}
+	}
+}
+
 type mockWriter struct {
 	headers http.Header
 }
@@ -0,0 +1,156 @@
+package just
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+)
+
+// Errors
+var (
+	ErrRequestBodyTooLarge = errors.New("request body too large")
+	ErrBodyNotFrozen       = errors.New("request body is streamed, the position can't be reset")
+)
+
+// Options of the request body of the application, the group or the route
+// (options of the route replace options of its groups and the application).
+type BodyOptions struct {
+	MaxSize     int64 // Maximum size of the body in bytes, larger requests get 413 (0 - unlimited).
+	Streaming   bool  // The body is not read before the handlers, it is streamed to them (ResetBodyReaderPosition is not available).
+	MemoryLimit int64 // Size of the body buffered in memory, the rest is written to a temporary file (0 - the whole body in memory).
+}
+
+// Body in the temporary file, the file is closed and removed after the request is processed.
+type tempFileBody struct {
+	*os.File
+}
+
+// Closing is deferred until the end of the request, so that the position of the body can be reset.
+func (b *tempFileBody) Close() error {
+	return nil
+}
+
+func (b *tempFileBody) remove() {
+	b.File.Close()
+	os.Remove(b.File.Name())
+}
+
+// Set the options of the request body of the last registered routes (or the group).
+// `app.POST("/upload", handler).SetBodyOptions(just.BodyOptions{MaxSize: 100 << 20, MemoryLimit: 1 << 20})`
+func (r *Router) SetBodyOptions(options BodyOptions) IRoute {
+	r.setBodyOptions(nil, options)
+	return r
+}
+
+func (r *Router) setBodyOptions(targets []*Router, options BodyOptions) {
+	root := r.rootRouter()
+	root.mutex.Lock()
+	defer root.mutex.Unlock()
+	if targets == nil {
+		targets = r.lastRoutes
+	}
+	if len(targets) < 1 {
+		targets = []*Router{r}
+	}
+	for _, target := range targets {
+		target.bodyOptions = &options
+	}
+}
+
+// Options of the request body of the route (the nearest router with options).
+func (r *Router) requestBodyOptions() BodyOptions {
+	root := r.rootRouter()
+	root.mutex.RLock()
+	defer root.mutex.RUnlock()
+	for g := r; g != nil; g = g.parent {
+		if g.bodyOptions != nil {
+			return *g.bodyOptions
+		}
+	}
+	return BodyOptions{}
+}
+
+// Reading of the body with the limit of the size, the body larger than the memory limit is written to the temporary file.
+func freezeBody(body io.Reader, maxSize, memoryLimit int64) (io.ReadCloser, error) {
+	if maxSize > 0 {
+		body = io.LimitReader(body, maxSize+1)
+	}
+	var buf bytes.Buffer
+	if memoryLimit > 0 {
+		if _, err := io.CopyN(&buf, body, memoryLimit+1); err == io.EOF {
+			// Тело поместилось в память, но могло быть обрезано ограничителем размера
+			if maxSize > 0 && int64(buf.Len()) > maxSize {
+				return nil, ErrRequestBodyTooLarge
+			}
+			return ioutil.NopCloser(bytes.NewReader(buf.Bytes())), nil
+		} else if err != nil {
+			return nil, err
+		}
+		// Остаток тела записывается во временный файл
+		f, err := ioutil.TempFile("", "just-body-")
+		if err != nil {
+			return nil, err
+		}
+		file := &tempFileBody{File: f}
+		size, err := io.Copy(f, io.MultiReader(&buf, body))
+		if err == nil && maxSize > 0 && size > maxSize {
+			err = ErrRequestBodyTooLarge
+		}
+		if err == nil {
+			_, err = f.Seek(0, io.SeekStart)
+		}
+		if err != nil {
+			file.remove()
+			return nil, err
+		}
+		return file, nil
+	}
+	if _, err := buf.ReadFrom(body); err != nil {
+		return nil, err
+	}
+	if maxSize > 0 && int64(buf.Len()) > maxSize {
+		return nil, ErrRequestBodyTooLarge
+	}
+	return ioutil.NopCloser(bytes.NewReader(buf.Bytes())), nil
+}
+
+// Preparing of the request body by the options of the route: reading (freezing) or streaming with the limit of the size.
+func (app *application) prepareRequestBody(c *Context, route *Router) IResponse {
+	req := c.Request
+	if !app.checkMethodForHaveBody(req.Method) || req.Body == nil || req.Body == http.NoBody {
+		return nil
+	}
+	options := route.requestBodyOptions()
+	if options.MaxSize > 0 && req.ContentLength > options.MaxSize {
+		return app.bodyTooLargeResponse(c, options.MaxSize)
+	}
+	if options.Streaming {
+		c.IsFrozenRequestBody = false
+		if options.MaxSize > 0 {
+			req.Body = http.MaxBytesReader(nil, req.Body, options.MaxSize)
+		}
+		return nil
+	}
+	body, err := freezeBody(req.Body, options.MaxSize, options.MemoryLimit)
+	if err == ErrRequestBodyTooLarge {
+		return app.bodyTooLargeResponse(c, options.MaxSize)
+	} else if err != nil {
+		return c.Serializer().Response(400, NewError("400", c.Trans("Invalid request body")))
+	}
+	req.Body.Close()
+	// Новое тело запроса с возможностью сбрасывания позиции чтения
+	req.Body = body
+	if file, ok := body.(*tempFileBody); ok {
+		c.bodyFile = file
+	}
+	return nil
+}
+
+func (app *application) bodyTooLargeResponse(c *Context, maxSize int64) IResponse {
+	return c.Serializer().Response(413, NewError("413", c.Trans("Request entity too large")).SetMetadata(H{
+		"max_size": maxSize,
+	}))
+}
@@ -0,0 +1,74 @@
+package just
+
+import (
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestRequestBodyOptions(t *testing.T) {
+	app := New().SetDefaultBodyOptions(BodyOptions{MaxSize: 10})
+	var tempFile string
+	read := func(c *Context) IResponse {
+		first, err := ioutil.ReadAll(c.Request.Body)
+		if err != nil {
+			return &Response{Status: 400, Bytes: []byte(err.Error())}
+		}
+		if err := c.ResetBodyReaderPosition(); err != nil {
+			return &Response{Status: 200, Bytes: append(first, []byte(" "+err.Error())...)}
+		}
+		second, _ := ioutil.ReadAll(c.Request.Body)
+		if c.bodyFile != nil {
+			tempFile = c.bodyFile.Name()
+		}
+		return &Response{Status: 200, Bytes: append(append(first, '|'), second...)}
+	}
+	app.POST("/small", read)
+	app.POST("/large", read).SetBodyOptions(BodyOptions{MaxSize: 100, MemoryLimit: 4})
+	app.POST("/memory", read).SetBodyOptions(BodyOptions{MaxSize: 10, MemoryLimit: 100})
+	app.Group("/stream").SetBodyOptions(BodyOptions{MaxSize: 10, Streaming: true}).(IRouter).POST("", read)
+	api := app.Versioned("/api", VersioningOptions{})
+	api.Version("1").POST("/upload", read).SetBodyOptions(BodyOptions{MaxSize: 4})
+	api.Version("2").SetBodyOptions(BodyOptions{MaxSize: 100}).(IRouter).POST("/upload", read)
+
+	tests := []struct {
+		path, body string
+		chunked    bool
+		status     int
+		result     string
+	}{
+		{"/small", "0123456789", false, 200, "0123456789|0123456789"},
+		{"/small", "0123456789ABCDEF", false, 413, ""},
+		{"/small", "0123456789ABCDEF", true, 413, ""},
+		{"/large", "0123456789ABCDEF", false, 200, "0123456789ABCDEF|0123456789ABCDEF"},
+		{"/memory", "0123456789", true, 200, "0123456789|0123456789"},
+		{"/memory", strings.Repeat("0123456789", 5), true, 413, ""},
+		{"/stream", "01234", false, 200, "01234 " + ErrBodyNotFrozen.Error()},
+		{"/stream", "0123456789ABCDEF", true, 400, ""},
+		{"/unknown", "0123456789ABCDEF", false, 413, ""},
+		{"/api/v1/upload", "0123456789", false, 413, ""},
+		{"/api/v1/upload", "0123456789", true, 413, ""},
+		{"/api/v1/upload", "0123", false, 200, "0123|0123"},
+		{"/api/v2/upload", "0123456789ABCDEF", false, 200, "0123456789ABCDEF|0123456789ABCDEF"},
+		{"/api/upload", "0123456789ABCDEF", true, 200, "0123456789ABCDEF|0123456789ABCDEF"},
+	}
+	for _, test := range tests {
+		req := httptest.NewRequest("POST", test.path, strings.NewReader(test.body))
+		if test.chunked {
+			req.ContentLength = -1
+		}
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+		if w.Code != test.status || (test.status == 200 && w.Body.String() != test.result) {
+			t.Errorf("%s %q: expected %d %q, got %d %q", test.path, test.body, test.status, test.result, w.Code, w.Body.String())
+		}
+	}
+	if len(tempFile) < 1 {
+		t.Fatal("expected the body in the temporary file")
+	}
+	if _, err := os.Stat(tempFile); !os.IsNotExist(err) {
+		t.Errorf("the temporary file %s is not removed", tempFile)
+	}
+}
@@ -1,13 +1,16 @@
 package just
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io/ioutil"
+	"math"
 	"mime/multipart"
 	"net"
 	"net/http"
 	"net/url"
+	"reflect"
 	"strconv"
 	"strings"
 	"time"
@@ -24,10 +27,15 @@ var (
 // Request Context struct.
 type Context struct {
 	// Private props.
-	app            IApplication      // Application.
-	routeInfo      IRouteInfo        // Current route info.
-	routeParams    map[string]string // Current route params.
-	handleIndex    int               // Current handler index.
+	app            IApplication         // Application.
+	routeInfo      IRouteInfo           // Current route info.
+	routeParams    map[string]string    // Current route params.
+	handleIndex    int                  // Current handler index.
+	allowedMethods []string             // Methods allowed for the path of the request (when the method is not allowed).
+	apiVersion     string               // Selected API version of the versioned group.
+	responseHeader http.Header          // Multi-valued headers added to the response (cookies).
+	bodyFile       *tempFileBody        // Temporary file of the request body (see BodyOptions.MemoryLimit).
+	cancels        []context.CancelFunc // Cancel functions of the request contexts (WithTimeout, WithDeadline, WithCancel).
 	isLocalRequest bool
 
 	// Public props.
@@ -38,6 +46,7 @@ type Context struct {
 
 func (c *Context) reset() *Context {
 	c.Request, c.routeInfo, c.routeParams, c.Meta, c.handleIndex = nil, nil, nil, nil, -1
+	c.allowedMethods, c.apiVersion, c.responseHeader, c.cancels = nil, "", nil, nil
 	c.IsFrozenRequestBody = true
 	return c
 }
@@ -47,6 +56,11 @@ func (c *Context) resetRoute(info IRouteInfo, params map[string]string) *Context
 	return c
 }
 
+// Information of the current route (name, path, metadata).
+func (c *Context) RouteInfo() IRouteInfo {
+	return c.routeInfo
+}
+
 // Current route path.
 func (c *Context) RouteBasePath() string {
 	if c.routeInfo != nil {
@@ -55,14 +69,36 @@ func (c *Context) RouteBasePath() string {
 	return ""
 }
 
+// Methods allowed for the path of the request, filled when the method of the request is not allowed (405).
+func (c *Context) AllowedMethods() []string {
+	return c.allowedMethods
+}
+
+// API version selected for the request by the versioned group (empty - the route is not versioned).
+func (c *Context) APIVersion() string {
+	return c.apiVersion
+}
+
+// Building the URL of the named route.
+func (c *Context) URLFor(name string, params H) (string, error) {
+	return c.app.URLFor(name, params)
+}
+
+// Current locale of the request (see LocaleMiddleware), otherwise the default locale of the translator.
+func (c *Context) Locale() string {
+	if locale, ok := c.GetStr("locale"); ok && len(locale) > 0 {
+		return locale
+	}
+	if translator := c.app.Translator(); translator != nil {
+		return translator.DefaultLocale()
+	}
+	return ""
+}
+
 // Translate text by current language.
 func (c *Context) Trans(message string, vars ...interface{}) string {
 	if translator := c.app.Translator(); translator != nil {
-		if i, ok := c.Get("locale"); ok {
-			if locale, valid := i.(string); valid {
-				return translator.Trans(locale, message, vars...)
-			}
-		}
+		return translator.Trans(c.Locale(), message, vars...)
 	}
 	if len(vars) > 0 {
 		return fmt.Sprintf(message, vars...)
@@ -85,7 +121,16 @@ func (c *Context) R(name string) IRenderer {
 	return c.Renderer(name)
 }
 
+// Name of the serializer of the response: the _format query, the FORMAT header, the _format form field
+// or the negotiation by the Accept header, otherwise the default serializer.
 func (c *Context) DetectedSerializerName() string {
+	name, _ := c.detectSerializerName()
+	return name
+}
+
+// Detection of the serializer name, false - no serializer is acceptable by the Accept header (the default name is returned).
+func (c *Context) detectSerializerName() (string, bool) {
+	m := c.app.SerializerManager()
 	var name string
 	if v, ok := c.Query("_format"); ok {
 		name = v
@@ -95,14 +140,21 @@ func (c *Context) DetectedSerializerName() string {
 		name = v
 	}
 	if len(name) > 1 {
-		if s := c.app.SerializerManager().Serializer(name, false); s != nil {
-			return name
+		if s := m.Serializer(name, false); s != nil {
+			return name, true
 		}
 	}
-	if defName, ok := c.app.SerializerManager().DefaultName(); ok {
-		return defName
+	acceptable := true
+	if accept, ok := c.RequestHeader("Accept"); ok && len(strings.TrimSpace(accept)) > 0 {
+		if name, ok := m.Negotiate(accept); ok {
+			return name, true
+		}
+		acceptable = false
+	}
+	if defName, ok := m.DefaultName(); ok {
+		return defName, acceptable
 	}
-	return "json"
+	return "json", acceptable
 }
 
 // Get serializer by name or content type (without names - default serializer, or auto detected serializer).
@@ -124,7 +176,14 @@ func (c *Context) Serializer(names ...string) ISerializer {
 			}
 		}
 	}
-	return m.Serializer(c.DetectedSerializerName(), false)
+	name, acceptable := c.detectSerializerName()
+	s := m.Serializer(name, false)
+	if !acceptable && s != nil {
+		return &notAcceptableSerializer{ISerializer: s, err: NewError("406", c.Trans("Not acceptable")).SetMetadata(H{
+			"accept": c.MustRequestHeader("Accept"),
+		})}
+	}
+	return s
 }
 
 // Short name for serializer method
@@ -159,6 +218,139 @@ func (c *Context) Bind(ptr interface{}) error {
 	return s.Deserialize(b, ptr)
 }
 
+// Binding of the request to the object in one pass: the body (or the query for GET and DELETE, see Bind)
+// and the fields with the path, query, header and cookie tags.
+// `ID int64 path:"id"`, `Page int query:"page"`, `Tenant string header:"X-Tenant"`, `Session string cookie:"sid"`.
+// Conversion errors of the fields are returned as BindErrors of *BindFieldError.
+func (c *Context) BindAll(ptr interface{}) error {
+	if c.Request == nil {
+		return ErrEmptyRequest
+	}
+	method := c.Request.Method
+	if method == "GET" || method == "DELETE" || (c.Request.Body != nil && c.Request.Body != http.NoBody && c.Request.ContentLength != 0) {
+		if err := c.Bind(ptr); err != nil {
+			return err
+		}
+	}
+	var query url.Values
+	if c.Request.URL != nil {
+		query = c.Request.URL.Query()
+	}
+	var result BindErrors
+	for _, source := range []struct {
+		tag    string
+		lookup func(name string) ([]string, bool)
+	}{
+		{BindSourcePath, func(name string) ([]string, bool) {
+			value, ok := c.Param(name)
+			return []string{value}, ok
+		}},
+		{BindSourceQuery, func(name string) ([]string, bool) {
+			values, ok := query[name]
+			return values, ok
+		}},
+		{BindSourceHeader, func(name string) ([]string, bool) {
+			values, ok := c.Request.Header[http.CanonicalHeaderKey(name)]
+			return values, ok
+		}},
+		{BindSourceCookie, func(name string) ([]string, bool) {
+			var values []string
+			for _, cookie := range c.Request.Cookies() {
+				if cookie.Name == name {
+					value, _ := url.QueryUnescape(cookie.Value)
+					values = append(values, value)
+				}
+			}
+			return values, len(values) > 0
+		}},
+	} {
+		for _, err := range mapTaggedValues(source.tag, source.lookup, ptr) {
+			if err == ErrBindOnlyStruct {
+				return err
+			}
+			result = append(result, err)
+		}
+	}
+	if len(result) > 0 {
+		return result
+	}
+	return nil
+}
+
+// Name of the serializer of the request data (form for the query of GET and DELETE).
+func (c *Context) requestSerializerName() string {
+	if c.Request.Method == "GET" || c.Request.Method == "DELETE" {
+		return "form"
+	}
+	if s := c.app.SerializerManager().Serializer(c.ContentType(), true); s != nil {
+		return s.Name()
+	}
+	return ""
+}
+
+// Binding of the request (see BindAll) and the validation by the valid tags of the object.
+// Returns the error with the code 400 (binding) or 422 (validation), causes contain all failed fields
+// by their names in the request (json, form, xml, path, query, header or cookie tags).
+func (c *Context) BindValid(ptr interface{}) *Error {
+	err := c.BindAll(ptr)
+	if err == ErrEmptyRequest || err == ErrBindOnlyStruct {
+		return NewError("400", c.Trans("Invalid request")).AddCause("", "", err.Error())
+	}
+	if err != nil {
+		e := NewError("400", c.Trans("Invalid request"))
+		if list, ok := err.(BindErrors); ok {
+			for _, item := range list {
+				if fe, ok := item.(*BindFieldError); ok {
+					e.AddCause(fe.Name, fe.Source, fe.Err.Error())
+				} else {
+					e.AddCause("", "", item.Error())
+				}
+			}
+		} else {
+			e.AddCause("", "body", err.Error())
+		}
+		return e
+	}
+	if list := Validation(ptr); len(list) > 0 {
+		e := NewError("422", c.Trans("Validation failed"))
+		t, serializerName := reflect.TypeOf(ptr), c.requestSerializerName()
+		// Поля без тега источника заполняются из тела (или query для GET и DELETE)
+		bodySource := "body"
+		if serializerName == "form" && (c.Request.Method == "GET" || c.Request.Method == "DELETE") {
+			bodySource = BindSourceQuery
+		}
+		for _, item := range list {
+			if ve, ok := item.(*ValidationError); ok {
+				name, source := requestFieldName(t, ve.Field, serializerName)
+				switch source {
+				case BindSourcePath, BindSourceQuery, BindSourceHeader, BindSourceCookie:
+				default:
+					source = bodySource
+				}
+				e.AddCause(name, source, ve.Message)
+			} else {
+				e.AddCause("", "", item.Error())
+			}
+		}
+		return e
+	}
+	return nil
+}
+
+// Binding and validation of the request (see BindValid), returns the 400 or 422 response with the error
+// by the detected serializer, nil - the object is valid.
+// `if res := c.BindValidResponse(&form); res != nil { return res }`
+func (c *Context) BindValidResponse(ptr interface{}) IResponse {
+	if err := c.BindValid(ptr); err != nil {
+		status := http.StatusBadRequest
+		if err.Code == "422" {
+			status = http.StatusUnprocessableEntity
+		}
+		return c.Serializer().Response(status, err)
+	}
+	return nil
+}
+
 func (c *Context) IsValid() bool {
 	return c.app != nil && c.Request != nil
 }
@@ -192,6 +384,28 @@ func (c *Context) Param(name string) (value string, ok bool) {
 	return
 }
 
+// Get route param by name converted by the converter of its type (see RegisterParamType).
+// Values of types without converter are converted by the kind of the destination.
+// `var id int; err := c.ParamAs("id", &id)`
+func (c *Context) ParamAs(name string, ptr interface{}) error {
+	str, ok := c.Param(name)
+	if !ok {
+		return ErrParamNotFound
+	}
+	var value interface{} = str
+	if route, ok := c.routeInfo.(*Router); ok {
+		if t, ok := route.paramType(name); ok {
+			if pt, ok := lookupParamType(t); ok && pt.converter != nil {
+				var err error
+				if value, err = pt.converter(str); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return assignParamValue(ptr, value)
+}
+
 // Get bool route param by name.
 func (c *Context) ParamBool(name string) (value bool, ok bool) {
 	if str, exist := c.Param(name); exist {
@@ -662,22 +876,38 @@ func (c *Context) MustCookie(name string) string {
 	return c.CookieDef(name, "")
 }
 
-// ClientIP implements a best effort algorithm to return the real client IP, it parses X-Real-IP and X-Forwarded-For in order to work properly with reverse-proxies such us: nginx or haproxy.
-// Use X-Forwarded-For before X-Real-Ip as nginx uses X-Real-Ip with the proxy's IP.
+// Headers added to any response of the request (values are not replaced by the headers of the response).
+func (c *Context) ResponseHeader() http.Header {
+	if c.responseHeader == nil {
+		c.responseHeader = make(http.Header)
+	}
+	return c.responseHeader
+}
+
+// Add the value of the header to the response of the request.
+func (c *Context) AddResponseHeader(key, value string) *Context {
+	c.ResponseHeader().Add(key, value)
+	return c
+}
+
+// Set the cookie in the response of the request.
+func (c *Context) SetCookie(cookie *Cookie) *Context {
+	if value := cookie.String(); len(value) > 0 {
+		c.AddResponseHeader("Set-Cookie", value)
+	}
+	return c
+}
+
+// Delete the cookie by the response of the request (Max-Age=0).
+func (c *Context) ClearCookie(name, path, domain string) *Context {
+	return c.SetCookie(clearingCookie(name, path, domain))
+}
+
+// ClientIP returns the IP of the client, forwarded - use the forwarding headers (Forwarded, X-Forwarded-For, X-Real-Ip)
+// of the trusted proxies (see SetTrustedProxies), the chain is walked from right to left up to the first untrusted address.
 func (c *Context) ClientIP(forwarded bool) string {
 	if forwarded {
-		clientIP := strings.TrimSpace(c.MustRequestHeader("X-Real-Ip"))
-		if len(clientIP) > 0 {
-			return clientIP
-		}
-		clientIP = c.MustRequestHeader("X-Forwarded-For")
-		if index := strings.IndexByte(clientIP, ','); index >= 0 {
-			clientIP = clientIP[0:index]
-		}
-		clientIP = strings.TrimSpace(clientIP)
-		if len(clientIP) > 0 {
-			return clientIP
-		}
+		return c.forwarded().clientIP
 	}
 	if ip, _, err := net.SplitHostPort(strings.TrimSpace(c.Request.RemoteAddr)); err == nil {
 		return ip
@@ -705,22 +935,30 @@ func (c *Context) ContentType() string {
 	return contentType
 }
 
-// Get url scheme from request, supported X-Scheme and X-Forwarded-Proto headers.
+// Get url scheme from request, the Forwarded, X-Forwarded-Proto and X-Scheme headers of the trusted proxies are supported.
 func (c *Context) RequestUrlScheme() string {
-	if value, ok := c.RequestHeader("X-Scheme"); ok && len(value) > 0 {
-		return strings.ToLower(strings.TrimSpace(value))
-	}
-	if value, ok := c.RequestHeader("X-Forwarded-Proto"); ok && len(value) > 0 && value != "http" {
-		return strings.ToLower(strings.TrimSpace(value))
+	if proto := c.forwarded().proto; len(proto) > 0 {
+		return proto
 	}
 	if c.Request.URL != nil {
 		if len(c.Request.URL.Scheme) > 1 {
 			return c.Request.URL.Scheme
 		}
 	}
+	if c.Request.TLS != nil {
+		return "https"
+	}
 	return "http"
 }
 
+// Get host (with port) requested by the client, the Forwarded and X-Forwarded-Host (X-Forwarded-Port) headers of the trusted proxies are supported.
+func (c *Context) RequestHost() string {
+	if host := c.forwarded().host; len(host) > 0 {
+		return host
+	}
+	return c.Request.Host
+}
+
 // Get request header string value by key.
 func (c *Context) RequestHeader(key string) (string, bool) {
 	if values, ok := c.Request.Header[key]; len(values) > 0 && ok {
@@ -741,12 +979,168 @@ func (c *Context) MustRequestHeader(key string) string {
 	return c.RequestHeaderDef(key, "")
 }
 
-// Reset body reader position.
+// Reset body reader position (not available for the streamed body).
 func (c *Context) ResetBodyReaderPosition() error {
+	if !c.IsFrozenRequestBody {
+		return ErrBodyNotFrozen
+	}
 	_, err := topSeekReader(c.Request.Body, true)
 	return err
 }
 
+// Removing the temporary file of the request body.
+func (c *Context) releaseBody() {
+	if c.bodyFile != nil {
+		c.bodyFile.remove()
+		c.bodyFile = nil
+	}
+}
+
 func (c *Context) IsLocalRequest() bool {
 	return c.isLocalRequest
 }
+
+// Implementation of context.Context, Deadline, Done and Err are delegated to the context of the request,
+// so handlers are cancelled when the client disconnects.
+// The context is reused after the request, it must not be used outside of the handlers (use Copy for goroutines).
+var _ context.Context = (*Context)(nil)
+
+// Deadline of the request context.
+func (c *Context) Deadline() (deadline time.Time, ok bool) {
+	if c.Request != nil {
+		return c.Request.Context().Deadline()
+	}
+	return
+}
+
+// Channel closed when the request is cancelled (the client disconnects) or its deadline expires.
+func (c *Context) Done() <-chan struct{} {
+	if c.Request != nil {
+		return c.Request.Context().Done()
+	}
+	return nil
+}
+
+// Reason of the cancellation of the request context (nil - not cancelled).
+func (c *Context) Err() error {
+	if c.Request != nil {
+		return c.Request.Context().Err()
+	}
+	return nil
+}
+
+// Value of the request context by the key, string keys are looked up in the metadata (Set) first.
+func (c *Context) Value(key interface{}) interface{} {
+	if name, ok := key.(string); ok && c.Meta != nil {
+		if value, ok := c.Meta[name]; ok {
+			return value
+		}
+	}
+	if c.Request != nil {
+		return c.Request.Context().Value(key)
+	}
+	return nil
+}
+
+// Replacing the context of the request, the next handlers get the new context.
+// The cancel function is also called after the response is written (including streams).
+func (c *Context) updateRequestContext(fn func(ctx context.Context) (context.Context, context.CancelFunc)) context.CancelFunc {
+	if c.Request == nil {
+		return func() {}
+	}
+	ctx, cancel := fn(c.Request.Context())
+	c.Request = c.Request.WithContext(ctx)
+	if cancel != nil {
+		c.cancels = append(c.cancels, cancel)
+	}
+	return cancel
+}
+
+// Releasing the contexts created by WithTimeout, WithDeadline and WithCancel.
+func (c *Context) releaseContext() {
+	for i := len(c.cancels) - 1; i >= 0; i-- {
+		c.cancels[i]()
+	}
+	c.cancels = nil
+}
+
+// Set the timeout of the request context, the context is released after the response is written,
+// the returned function cancels it earlier (do not defer it in middleware of stream responses).
+// `c.WithTimeout(5 * time.Second)`
+func (c *Context) WithTimeout(timeout time.Duration) context.CancelFunc {
+	return c.updateRequestContext(func(ctx context.Context) (context.Context, context.CancelFunc) {
+		return context.WithTimeout(ctx, timeout)
+	})
+}
+
+// Set the deadline of the request context, the context is released after the response is written,
+// the returned function cancels it earlier.
+func (c *Context) WithDeadline(deadline time.Time) context.CancelFunc {
+	return c.updateRequestContext(func(ctx context.Context) (context.Context, context.CancelFunc) {
+		return context.WithDeadline(ctx, deadline)
+	})
+}
+
+// Make the request context cancelable, the returned function cancels it
+// (otherwise it is cancelled after the response is written).
+func (c *Context) WithCancel() context.CancelFunc {
+	return c.updateRequestContext(context.WithCancel)
+}
+
+// Set the value of the request context by the key (see context.WithValue).
+func (c *Context) WithValue(key, value interface{}) *Context {
+	c.updateRequestContext(func(ctx context.Context) (context.Context, context.CancelFunc) {
+		return context.WithValue(ctx, key, value), nil
+	})
+	return c
+}
+
+// Context without the cancellation and the deadline of the parent, values of the parent are available.
+type detachedContext struct {
+	parent context.Context
+}
+
+func (detachedContext) Deadline() (deadline time.Time, ok bool) {
+	return
+}
+
+func (detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (detachedContext) Err() error {
+	return nil
+}
+
+func (d detachedContext) Value(key interface{}) interface{} {
+	return d.parent.Value(key)
+}
+
+// Copy of the context for use outside of the request (goroutines, background tasks),
+// the copy is not reused, it is not cancelled with the request and does not run the handlers (Next).
+func (c *Context) Copy() *Context {
+	cp := &Context{
+		app:                 c.app,
+		routeInfo:           c.routeInfo,
+		handleIndex:         math.MaxInt32,
+		apiVersion:          c.apiVersion,
+		isLocalRequest:      c.isLocalRequest,
+		IsFrozenRequestBody: c.IsFrozenRequestBody,
+	}
+	if c.routeParams != nil {
+		cp.routeParams = make(map[string]string, len(c.routeParams))
+		for key, value := range c.routeParams {
+			cp.routeParams[key] = value
+		}
+	}
+	if c.Meta != nil {
+		cp.Meta = make(map[string]interface{}, len(c.Meta))
+		for key, value := range c.Meta {
+			cp.Meta[key] = value
+		}
+	}
+	if c.Request != nil {
+		cp.Request = c.Request.WithContext(detachedContext{parent: c.Request.Context()})
+	}
+	return cp
+}
@@ -0,0 +1,188 @@
+package just
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type contextTestKey struct{}
+
+func TestContextAsStdContext(t *testing.T) {
+	app := New()
+	var deadline time.Time
+	var hasDeadline bool
+	var value, meta interface{}
+	var err error
+	app.GET("/", func(c *Context) IResponse {
+		c.Set("user", "john")
+		defer c.WithTimeout(time.Millisecond)()
+		return c.Next()
+	}, func(c *Context) IResponse {
+		var ctx context.Context = c
+		deadline, hasDeadline = ctx.Deadline()
+		value, meta = ctx.Value(contextTestKey{}), ctx.Value("user")
+		<-ctx.Done()
+		err = ctx.Err()
+		return &Response{Status: 200}
+	})
+	req := httptest.NewRequest("GET", "/", nil)
+	req = req.WithContext(context.WithValue(req.Context(), contextTestKey{}, "value"))
+	app.ServeHTTP(httptest.NewRecorder(), req)
+	if !hasDeadline || deadline.IsZero() || err != context.DeadlineExceeded {
+		t.Errorf("unexpected deadline %v (%v), err %v", deadline, hasDeadline, err)
+	}
+	if value != "value" || meta != "john" {
+		t.Errorf("unexpected values %v, %v", value, meta)
+	}
+}
+
+func TestSerializerNegotiation(t *testing.T) {
+	app := New()
+	app.GET("/item", func(c *Context) IResponse {
+		return c.S().Response(200, H{"name": "item"})
+	})
+	app.GET("/error", func(c *Context) IResponse {
+		return c.S().Response(400, NewError("400", "bad request"))
+	})
+	tests := []struct {
+		path, accept string
+		status       int
+		contentType  string
+	}{
+		{"/item", "", 200, "application/json"},
+		{"/item", "*/*", 200, "application/json"},
+		{"/item", "text/html, application/xml;q=0.9, */*;q=0.8", 200, "application/json"},
+		{"/item", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8", 200, "application/json"},
+		{"/item", "application/xhtml+xml", 406, "application/json"},
+		{"/item", "application/xml, */*;q=0.5", 200, "application/xml"},
+		{"/item", "application/xml, */*;q=0.9", 200, "application/xml"},
+		{"/item", "application/xml, application/*;q=0.9", 200, "application/xml"},
+		{"/item", "application/xml, application/json;q=0.95", 200, "application/xml"},
+		{"/item", "application/xml;q=0.9, */*;q=0.8", 200, "application/xml"},
+		{"/item", "application/xml;q=0.5, application/json;q=0.5", 200, "application/json"},
+		{"/item", "application/*", 200, "application/json"},
+		{"/item", "application/json;q=0.5, text/xml", 200, "application/xml"},
+		{"/item", "application/json;q=0, */*", 200, "application/xml"},
+		{"/item", "application/problem+xml, application/json;q=0.5", 200, "application/xml"},
+		{"/item", "text/html", 406, "application/json"},
+		{"/item", "application/x-www-form-urlencoded", 406, "application/json"},
+		{"/item?_format=xml", "text/html", 200, "application/xml"},
+		{"/error", "text/html", 400, "application/json"},
+	}
+	for _, test := range tests {
+		req := httptest.NewRequest("GET", test.path, nil)
+		if len(test.accept) > 0 {
+			req.Header.Set("Accept", test.accept)
+		}
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+		if w.Code != test.status || mediaTypeOf(w.Header().Get(ContentTypeHeaderKey)) != test.contentType {
+			t.Errorf("%s %q: expected %d %s, got %d %s", test.path, test.accept, test.status, test.contentType, w.Code, w.Header().Get(ContentTypeHeaderKey))
+		}
+	}
+}
+
+func TestLocaleMiddleware(t *testing.T) {
+	app := New()
+	app.Translator().SetDefaultLocale("en").
+		AddTranslationMap("en", TranslationMap{"Hello": "Hello"}).
+		AddTranslationMap("ru", TranslationMap{"Hello": "Привет"}).
+		AddTranslationMap("pt-BR", TranslationMap{"Hello": "Olá"})
+	handler := func(c *Context) IResponse {
+		return &Response{Status: 200, Bytes: []byte(c.Locale() + " " + c.Tr("Hello"))}
+	}
+	app.Use(LocaleMiddleware(LocaleOptions{}))
+	app.GET("/hello", handler)
+	app.GET("/{lang}/hello", handler)
+	app.Group("/custom", LocaleMiddleware(LocaleOptions{
+		Resolver: func(c *Context) string { return c.MustRequestHeader("X-Locale") },
+	})).GET("/hello", handler)
+
+	tests := []struct {
+		path, acceptLanguage, cookie, header string
+		expected                             string
+	}{
+		{"/hello", "", "", "", "en Hello"},
+		{"/hello", "de, ru;q=0.8, en;q=0.5", "", "", "ru Привет"},
+		{"/hello", "pt-PT, en;q=0.1", "", "", "pt-BR Olá"},
+		{"/hello", "ru-RU", "", "", "ru Привет"},
+		{"/hello?lang=ru", "en", "", "", "ru Привет"},
+		{"/hello", "en", "ru", "", "ru Привет"},
+		{"/ru/hello", "en", "", "", "ru Привет"},
+		{"/de/hello", "", "", "", "en Hello"},
+		{"/custom/hello", "en", "", "ru", "ru Привет"},
+	}
+	for _, test := range tests {
+		req := httptest.NewRequest("GET", test.path, nil)
+		req.Header.Set("Accept-Language", test.acceptLanguage)
+		if len(test.cookie) > 0 {
+			req.AddCookie(&http.Cookie{Name: "lang", Value: test.cookie})
+		}
+		if len(test.header) > 0 {
+			req.Header.Set("X-Locale", test.header)
+		}
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+		if w.Body.String() != test.expected {
+			t.Errorf("%s %q: expected %q, got %q", test.path, test.acceptLanguage, test.expected, w.Body.String())
+		}
+	}
+}
+
+// Translator without the list of locales.
+type staticTranslator struct{}
+
+func (staticTranslator) DefaultLocale() string                                  { return "de" }
+func (t staticTranslator) SetDefaultLocale(string) ITranslator                  { return t }
+func (t staticTranslator) AddTranslationMap(string, TranslationMap) ITranslator { return t }
+func (staticTranslator) Trans(locale string, message string, vars ...interface{}) string {
+	return message
+}
+
+func TestLocaleMiddlewareWithoutLocales(t *testing.T) {
+	app := New().SetTranslator(staticTranslator{})
+	app.Use(LocaleMiddleware(LocaleOptions{}))
+	app.GET("/hello", func(c *Context) IResponse {
+		return &Response{Status: 200, Bytes: []byte(c.Locale())}
+	})
+	req := httptest.NewRequest("GET", "/hello?lang=ru", nil)
+	req.Header.Set("Accept-Language", "ru, en;q=0.5")
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+	if w.Body.String() != "de" {
+		t.Errorf("expected default locale, got %q", w.Body.String())
+	}
+}
+
+func TestContextReleaseAndCopy(t *testing.T) {
+	app := New()
+	var requestCtx context.Context
+	var copied *Context
+	app.Use(func(c *Context) IResponse {
+		c.WithTimeout(time.Second)
+		c.Set("user", "john")
+		return c.Next()
+	})
+	app.GET("/events", func(c *Context) IResponse {
+		requestCtx, copied = c.Request.Context(), c.Copy()
+		return SSEResponse(func(s *SSEWriter) {
+			if err := s.Data("first"); err != nil {
+				t.Error(err)
+			}
+		}, SSEOptions{KeepAlive: -1})
+	})
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/events", nil))
+	if w.Body.String() != "data: first\n\n" {
+		t.Errorf("unexpected stream %q", w.Body.String())
+	}
+	if requestCtx.Err() != context.Canceled {
+		t.Errorf("expected the released context, got %v", requestCtx.Err())
+	}
+	if copied.Err() != nil || copied.Done() != nil || copied.Value("user") != "john" || copied.Next() != nil {
+		t.Errorf("unexpected copy of the context: %v", copied.Err())
+	}
+}
@@ -0,0 +1,45 @@
+package just
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Cookie of the response, the value is escaped (Context.Cookie returns it unescaped).
+type Cookie struct {
+	Name        string
+	Value       string
+	Path        string
+	Domain      string
+	Expires     time.Time
+	MaxAge      int           // 0 - not set, negative - delete the cookie now (Max-Age=0).
+	Secure      bool          // Send only over HTTPS.
+	HttpOnly    bool          // Not available for JavaScript.
+	SameSite    http.SameSite // SameSite attribute (http.SameSiteLaxMode, http.SameSiteStrictMode, http.SameSiteNoneMode).
+	Partitioned bool          // Partitioned attribute (CHIPS), requires Secure.
+}
+
+// Value of the Set-Cookie header (empty - invalid name of the cookie).
+func (c *Cookie) String() string {
+	cookie := http.Cookie{
+		Name:     c.Name,
+		Value:    url.QueryEscape(c.Value),
+		Path:     c.Path,
+		Domain:   c.Domain,
+		Expires:  c.Expires,
+		MaxAge:   c.MaxAge,
+		Secure:   c.Secure,
+		HttpOnly: c.HttpOnly,
+		SameSite: c.SameSite,
+	}
+	value := cookie.String()
+	if c.Partitioned && len(value) > 0 {
+		value += "; Partitioned"
+	}
+	return value
+}
+
+func clearingCookie(name, path, domain string) *Cookie {
+	return &Cookie{Name: name, Path: path, Domain: domain, MaxAge: -1, Expires: time.Unix(0, 0)}
+}
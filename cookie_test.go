@@ -0,0 +1,57 @@
+package just
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMultiHeadersAndCookies(t *testing.T) {
+	app := New()
+	app.Use(func(c *Context) IResponse {
+		c.SetCookie(&Cookie{Name: "sid", Value: "a b", Path: "/", HttpOnly: true, Secure: true, SameSite: http.SameSiteStrictMode})
+		return c.Next()
+	})
+	app.GET("/", func(c *Context) IResponse {
+		c.ClearCookie("old", "/", "")
+		res := &Response{Status: 200, Headers: map[string]string{"Vary": "Origin"}}
+		return res.AddHeader("Vary", "Accept").
+			AddHeader("Link", "</a.css>; rel=preload").
+			AddHeader("Link", "</b.js>; rel=preload").
+			SetCookie(&Cookie{Name: "theme", Value: "dark", MaxAge: 3600, Secure: true, SameSite: http.SameSiteNoneMode, Partitioned: true})
+	})
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+	header := w.Header()
+	if vary := header["Vary"]; len(vary) != 2 || vary[0] != "Origin" || vary[1] != "Accept" {
+		t.Errorf("unexpected Vary %v", vary)
+	}
+	if links := header["Link"]; len(links) != 2 {
+		t.Errorf("unexpected Link %v", links)
+	}
+	cookies := header["Set-Cookie"]
+	expected := []string{
+		"sid=a+b; Path=/; HttpOnly; Secure; SameSite=Strict",
+		"old=; Path=/; Expires=Thu, 01 Jan 1970 00:00:00 GMT; Max-Age=0",
+		"theme=dark; Max-Age=3600; Secure; SameSite=None; Partitioned",
+	}
+	if len(cookies) != len(expected) {
+		t.Fatalf("unexpected cookies %v", cookies)
+	}
+	for i := range expected {
+		if cookies[i] != expected[i] {
+			t.Errorf("expected cookie %q, got %q", expected[i], cookies[i])
+		}
+	}
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.AddCookie(&http.Cookie{Name: "sid", Value: "a+b"})
+	ctx := &Context{app: app, Request: req}
+	if value, err := ctx.Cookie("sid"); err != nil || value != "a b" {
+		t.Errorf("unexpected cookie value %q (%v)", value, err)
+	}
+	if res := app.LocalDo(httptest.NewRequest("GET", "/", nil)); len(res.(IMultiHeadersResponse).GetMultiHeaders()["Set-Cookie"]) != 3 {
+		t.Error("expected cookies of the context in the local response")
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"net/url"
 	"reflect"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -13,8 +14,42 @@ var (
 	ErrUnknownType         = errors.New("unknown type")
 	ErrBlankTimeFormat     = errors.New("blank time format")
 	ErrOnlyStructUrlEncode = errors.New("array and slice by root element not supported, only structure")
+	ErrBindOnlyStruct      = errors.New("binding supports only pointer to structure")
 )
 
+// Sources of the values of the request bound by the tags of the fields (Context.BindAll).
+const (
+	BindSourcePath   = "path"
+	BindSourceQuery  = "query"
+	BindSourceHeader = "header"
+	BindSourceCookie = "cookie"
+)
+
+// Error of the conversion of the request value to the field of the structure.
+type BindFieldError struct {
+	Field  string // Name of the field of the structure.
+	Source string // Source of the value (path, query, header, cookie).
+	Name   string // Name of the value in the source.
+	Err    error  // Conversion error.
+}
+
+// Bind field error text.
+func (e *BindFieldError) Error() string {
+	return "Invalid \"" + e.Field + "\" (" + e.Source + " \"" + e.Name + "\") - " + e.Err.Error()
+}
+
+// Errors of the fields of the binding.
+type BindErrors []error
+
+// Bind errors text.
+func (e BindErrors) Error() string {
+	list := make([]string, len(e))
+	for i, err := range e {
+		list[i] = err.Error()
+	}
+	return strings.Join(list, "; ")
+}
+
 func marshalUrlValues(ptr interface{}) ([]byte, error) {
 	t, v := reflect.TypeOf(ptr).Elem(), reflect.ValueOf(ptr).Elem()
 	if t.Kind() == reflect.Array || t.Kind() == reflect.Slice {
@@ -89,37 +124,78 @@ func recursiveTreeMapForm(values map[string][]string, files map[string][]*multip
 			}
 			continue
 		}
-		if numElements := len(inputValue); numElements > 0 {
-			if structFieldKind == reflect.Slice || structFieldKind == reflect.Array {
-				sliceOf := structField.Type().Elem().Kind()
-				slice := reflect.MakeSlice(structField.Type(), numElements, numElements)
-				for i := 0; i < numElements; i++ {
-					if err := setWithProperType(sliceOf, inputValue[i], slice.Index(i)); err != nil {
-						return err
-					}
-				}
-				v.Field(i).Set(slice)
-			} else {
-				if _, isTime := structField.Interface().(time.Time); isTime {
-					if err := setTimeField(inputValue[0], typeField, structField); err != nil {
-						return err
-					}
-					continue
-				}
-				kind := typeField.Type.Kind()
-				if kind == reflect.Ptr {
-					kind = typeField.Type.Elem().Kind()
-					if structField.IsNil() {
-						structField.Set(reflect.New(typeField.Type.Elem()))
-					}
-				}
-				if err := setWithProperType(kind, inputValue[0], reflect.Indirect(structField)); err != nil {
-					return err
+		if err := setFieldValues(inputValue, typeField, structField); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Setting the values to the field of the structure, slices get all values, other fields - the first one.
+func setFieldValues(values []string, typeField reflect.StructField, structField reflect.Value) error {
+	numElements := len(values)
+	if numElements < 1 {
+		return nil
+	}
+	structFieldKind := structField.Kind()
+	if structFieldKind == reflect.Slice || structFieldKind == reflect.Array {
+		sliceOf := structField.Type().Elem().Kind()
+		slice := reflect.MakeSlice(structField.Type(), numElements, numElements)
+		for i := 0; i < numElements; i++ {
+			if err := setWithProperType(sliceOf, values[i], slice.Index(i)); err != nil {
+				return err
+			}
+		}
+		structField.Set(slice)
+		return nil
+	}
+	if _, isTime := structField.Interface().(time.Time); isTime {
+		return setTimeField(values[0], typeField, structField)
+	}
+	kind := typeField.Type.Kind()
+	if kind == reflect.Ptr {
+		kind = typeField.Type.Elem().Kind()
+		if structField.IsNil() {
+			structField.Set(reflect.New(typeField.Type.Elem()))
+		}
+	}
+	return setWithProperType(kind, values[0], reflect.Indirect(structField))
+}
+
+// Mapping of the values of the request source (path, query, header, cookie) to the fields with the tag of the source.
+// Returns the errors of the fields (*BindFieldError).
+func mapTaggedValues(tag string, lookup func(name string) ([]string, bool), ptr interface{}) []error {
+	t, v := reflect.TypeOf(ptr), reflect.ValueOf(ptr)
+	if t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Struct || v.IsNil() {
+		return []error{ErrBindOnlyStruct}
+	}
+	return recursiveMapTaggedValues(tag, lookup, t.Elem(), v.Elem())
+}
+
+func recursiveMapTaggedValues(tag string, lookup func(name string) ([]string, bool), t reflect.Type, v reflect.Value) []error {
+	var result []error
+	for i := 0; i < t.NumField(); i++ {
+		typeField, structField := t.Field(i), v.Field(i)
+		name := typeField.Tag.Get(tag)
+		if len(name) < 1 || name == "-" {
+			// Вложенные структуры без тега источника обходятся рекурсивно
+			if typeField.Type.Kind() == reflect.Struct && structField.CanSet() {
+				if _, isTime := structField.Interface().(time.Time); !isTime {
+					result = append(result, recursiveMapTaggedValues(tag, lookup, typeField.Type, structField)...)
 				}
 			}
+			continue
+		}
+		if !structField.CanSet() {
+			continue
+		}
+		if values, ok := lookup(name); ok {
+			if err := setFieldValues(values, typeField, structField); err != nil {
+				result = append(result, &BindFieldError{Field: typeField.Name, Source: tag, Name: name, Err: err})
+			}
 		}
 	}
-	return nil
+	return result
 }
 
 func setWithProperType(valueKind reflect.Kind, val string, structField reflect.Value) error {
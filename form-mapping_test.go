@@ -0,0 +1,54 @@
+package just
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type bindAllTest struct {
+	ID      int64     `path:"id"`
+	Page    int       `query:"page"`
+	Tags    []string  `query:"tag"`
+	Since   time.Time `query:"since" time_format:"2006-01-02" time_utc:"true"`
+	Tenant  string    `header:"X-Tenant"`
+	Session *string   `cookie:"sid"`
+	Name    string    `json:"name"`
+}
+
+func TestBindAll(t *testing.T) {
+	app := New()
+	var result bindAllTest
+	var bindErr error
+	app.POST("/users/{id:int}", func(c *Context) IResponse {
+		result = bindAllTest{}
+		bindErr = c.BindAll(&result)
+		return &Response{Status: 200}
+	})
+
+	req := httptest.NewRequest("POST", "/users/12?page=3&tag=a&tag=b&since=2020-05-01", strings.NewReader(`{"name":"John"}`))
+	req.Header.Set(ContentTypeHeaderKey, "application/json")
+	req.Header.Set("X-Tenant", "acme")
+	req.AddCookie(&http.Cookie{Name: "sid", Value: "a%20b"})
+	app.ServeHTTP(httptest.NewRecorder(), req)
+	if bindErr != nil {
+		t.Fatal(bindErr)
+	}
+	if result.ID != 12 || result.Page != 3 || len(result.Tags) != 2 || result.Tags[1] != "b" ||
+		!result.Since.Equal(time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC)) ||
+		result.Tenant != "acme" || result.Session == nil || *result.Session != "a b" || result.Name != "John" {
+		t.Errorf("unexpected result %+v", result)
+	}
+
+	req = httptest.NewRequest("POST", "/users/12?page=x&since=01.05.2020", nil)
+	app.ServeHTTP(httptest.NewRecorder(), req)
+	errs, ok := bindErr.(BindErrors)
+	if !ok || len(errs) != 2 {
+		t.Fatalf("expected two field errors, got %v", bindErr)
+	}
+	if e, ok := errs[0].(*BindFieldError); !ok || e.Field != "Page" || e.Source != BindSourceQuery || e.Name != "page" {
+		t.Errorf("unexpected error %v", errs[0])
+	}
+}
@@ -2,6 +2,8 @@ package just
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 	"sync"
 )
 
@@ -16,6 +18,11 @@ type ITranslator interface {
 	Trans(locale string, message string, vars ...interface{}) string // Translate text for locale and vars.
 }
 
+// Translator with the list of locales, used by LocaleMiddleware (without it the default locale is used).
+type ILocalesTranslator interface {
+	Locales() []string // Locales of the added translation maps.
+}
+
 type baseTranslator struct {
 	sync.RWMutex
 	defaultLocale string
@@ -29,6 +36,18 @@ func (t *baseTranslator) DefaultLocale() string {
 	return t.defaultLocale
 }
 
+func (t *baseTranslator) Locales() []string {
+	t.RLock()
+	defer t.RUnlock()
+
+	locales := make([]string, 0, len(t.localizations))
+	for locale := range t.localizations {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
 func (t *baseTranslator) SetDefaultLocale(locale string) ITranslator {
 	t.RLock()
 	defer t.RUnlock()
@@ -68,3 +87,110 @@ func (t *baseTranslator) Trans(locale string, message string, vars ...interface{
 	}
 	return message
 }
+
+// Name of the query parameter, the cookie and the route parameter with the locale by default.
+const defaultLocaleParamName = "lang"
+
+// Options of the locale detection.
+type LocaleOptions struct {
+	Resolver   func(c *Context) string // Resolver of the locale checked first (empty - not resolved).
+	RouteParam string                  // Route parameter of the URL prefix group (/{lang}/...), default lang, "-" - not used.
+	QueryParam string                  // Query parameter, default lang, "-" - not used.
+	CookieName string                  // Cookie, default lang, "-" - not used.
+
+	DisableAcceptLanguage bool // Do not use the Accept-Language header.
+}
+
+// Normalized language tag (en_US -> en-us).
+func normalizeLocale(locale string) string {
+	return strings.ToLower(strings.Replace(strings.TrimSpace(locale), "_", "-", -1))
+}
+
+// Selection of the supported locale by the language tag (RFC 4647 lookup):
+// en-US matches en-US, then en, then the first supported locale of the language (en-GB).
+func matchLocale(supported []string, tag string) (string, bool) {
+	tag = normalizeLocale(tag)
+	if len(tag) < 1 || tag == "*" {
+		return "", false
+	}
+	for prefix := tag; len(prefix) > 0; {
+		for _, locale := range supported {
+			if normalizeLocale(locale) == prefix {
+				return locale, true
+			}
+		}
+		i := strings.LastIndexByte(prefix, '-')
+		if i < 0 {
+			break
+		}
+		prefix = prefix[:i]
+	}
+	language := tag
+	if i := strings.IndexByte(language, '-'); i > 0 {
+		language = language[:i]
+	}
+	for _, locale := range supported {
+		if strings.HasPrefix(normalizeLocale(locale), language+"-") {
+			return locale, true
+		}
+	}
+	return "", false
+}
+
+// Detection of the locale of the request among the locales of the translator,
+// the locale is available by Context.Locale and is used by Context.Trans.
+// Order: the resolver, the route parameter, the query parameter, the cookie, the Accept-Language header (with q-values),
+// otherwise the default locale of the translator (always for translators without ILocalesTranslator).
+func LocaleMiddleware(options LocaleOptions) HandlerFunc {
+	names := []*string{&options.RouteParam, &options.QueryParam, &options.CookieName}
+	for _, name := range names {
+		if len(*name) < 1 {
+			*name = defaultLocaleParamName
+		} else if *name == "-" {
+			*name = ""
+		}
+	}
+	return func(c *Context) IResponse {
+		translator := c.app.Translator()
+		if translator == nil {
+			return c.Next()
+		}
+		var supported []string
+		if t, ok := translator.(ILocalesTranslator); ok {
+			supported = t.Locales()
+		}
+		var candidates []string
+		if options.Resolver != nil {
+			candidates = append(candidates, options.Resolver(c))
+		}
+		if len(options.RouteParam) > 0 {
+			if value, ok := c.Param(options.RouteParam); ok {
+				candidates = append(candidates, value)
+			}
+		}
+		if len(options.QueryParam) > 0 {
+			if value, ok := c.Query(options.QueryParam); ok {
+				candidates = append(candidates, value)
+			}
+		}
+		if len(options.CookieName) > 0 {
+			if value, err := c.Cookie(options.CookieName); err == nil {
+				candidates = append(candidates, value)
+			}
+		}
+		if !options.DisableAcceptLanguage {
+			for _, r := range parseAcceptHeader(c.MustRequestHeader("Accept-Language")) {
+				candidates = append(candidates, r.mediaType)
+			}
+		}
+		locale := translator.DefaultLocale()
+		for _, candidate := range candidates {
+			if matched, ok := matchLocale(supported, candidate); ok {
+				locale = matched
+				break
+			}
+		}
+		c.Set("locale", locale)
+		return c.Next()
+	}
+}
@@ -1,11 +1,11 @@
 package just
 
 import (
-	"bytes"
 	"errors"
 	"fmt"
-	"io/ioutil"
+	"net"
 	"net/http"
+	"net/url"
 	"os"
 	"runtime/debug"
 	"strconv"
@@ -38,6 +38,7 @@ type IApplication interface {
 	Translator() ITranslator
 	SerializerManager() ISerializerManager
 	TemplatingManager() ITemplatingManager
+	TemplateFuncs() map[string]interface{}
 
 	LocalDo(req *http.Request) IResponse
 
@@ -45,6 +46,19 @@ type IApplication interface {
 	SetTranslator(t ITranslator) IApplication
 	SetNoRouteHandler(handler HandlerFunc) IApplication
 	SetNoImplementedHandler(handler HandlerFunc) IApplication
+	SetMethodNotAllowedHandler(handler HandlerFunc) IApplication
+	RegisterParamType(name, pattern string, converter ParamConverter) IApplication
+	SetRedirectTrailingSlash(enable bool) IApplication
+	SetRedirectFixedPath(enable, caseInsensitive bool) IApplication
+	SetRouteConflictsAsErrors(enable bool) IApplication
+	SetDefaultBodyOptions(options BodyOptions) IApplication
+	SetTrustedProxies(proxies ...string) IApplication
+
+	// The proxy is trusted to send the forwarding headers (see SetTrustedProxies).
+	IsTrustedProxy(ip string) bool
+
+	// Route conflicts detected at registration (see SetRouteConflictsAsErrors).
+	RouteConflicts() []error
 
 	ServeHTTP(w http.ResponseWriter, req *http.Request)
 
@@ -57,8 +71,14 @@ type application struct {
 	pool sync.Pool
 
 	// Стандартные обработчики ошибок
-	noRouteHandler       HandlerFunc
-	noImplementedHandler HandlerFunc
+	noRouteHandler          HandlerFunc
+	noImplementedHandler    HandlerFunc
+	methodNotAllowedHandler HandlerFunc
+
+	// Редиректы на канонический путь роута
+	redirectTrailingSlash bool
+	redirectFixedPath     bool
+	caseInsensitivePath   bool
 
 	// Менеджер сериализаторов с поддержкой многопоточности
 	serializerManager serializerManager
@@ -71,6 +91,9 @@ type application struct {
 
 	// Менеджер профилирования
 	profiler IProfiler
+
+	// Доверенные прокси (nil - только loopback)
+	trustedProxies []*net.IPNet
 }
 
 func (app *application) printWelcomeMessage(address string, tls bool) {
@@ -94,6 +117,16 @@ func (app *application) TemplatingManager() ITemplatingManager {
 	return &app.templatingManager
 }
 
+// Functions of the application for templates (url, ...), they are added by SetRenderer,
+// a renderer loading templates before SetRenderer must add them itself before loading.
+func (app *application) TemplateFuncs() map[string]interface{} {
+	funcMap := make(map[string]interface{}, len(app.templatingManager.funcMap))
+	for name, i := range app.templatingManager.funcMap {
+		funcMap[name] = i
+	}
+	return funcMap
+}
+
 func (app *application) SetProfiler(p IProfiler) IApplication {
 	app.profiler = p
 	return app
@@ -130,14 +163,8 @@ func (app *application) handleHttpRequest(w http.ResponseWriter, c *Context) {
 		return
 	}
 	httpMethod, path := c.Request.Method, c.Request.URL.Path
-	if app.checkMethodForHaveBody(httpMethod) && c.Request.Body != nil {
-		// Преобразовываем данные
-		if b, _ := ioutil.ReadAll(c.Request.Body); len(b) > 0 {
-			c.Request.Body.Close()
-			// Новое тело запроса с возможностью сбрасывания позиции чтения
-			c.Request.Body = ioutil.NopCloser(bytes.NewReader(b))
-		}
-	}
+	defer c.releaseBody()
+	defer c.releaseContext()
 	if app.profiler != nil {
 		// Фиксация начала обработки запроса
 		app.profiler.OnStartRequest(c.Request)
@@ -161,7 +188,7 @@ func (app *application) handleHttpRequest(w http.ResponseWriter, c *Context) {
 		}
 	}()
 	// Выполняем handlers из роутеров
-	response, existRoute := app.handleRouter(&app.Router, httpMethod, path, c)
+	response, existRoute := app.handleRouter(c)
 	// Если ответ пустой
 	if response == nil {
 		// Если ответа так и нет, но был найден роут -> выдаем ошибку пустого ответа
@@ -169,10 +196,12 @@ func (app *application) handleHttpRequest(w http.ResponseWriter, c *Context) {
 			// 501 ошибка
 			response = app.noImplementedHandler(c)
 		} else {
-			// Если ничего так и нет, выводим 404 ошибку
+			// Если ничего так и нет, выводим 404 (405) ошибку
 			// но перед этим прогоняем все обработчики
-			if response = c.resetRoute(app, nil).Next(); response == nil {
-				response = app.noRouteHandler(c)
+			if response = app.prepareRequestBody(c, &app.Router); response == nil {
+				if response = c.resetRoute(app, nil).Next(); response == nil {
+					response = app.handleNoRoute(c, httpMethod, path)
+				}
 			}
 		}
 	}
@@ -183,11 +212,14 @@ func (app *application) handleHttpRequest(w http.ResponseWriter, c *Context) {
 	// Отправляем response клиенту
 	if response != nil {
 		if streamFunc, ok := response.GetStreamHandler(); ok {
+			writeMultiHeaders(w, c, response)
 			streamFunc(w, c.Request)
 		} else {
+			var headers map[string]string
+			hasRedirect, hasServeFiles := false, false
 			if response.HasHeaders() {
 				// Обработка заголовков
-				headers, hasRedirect, hasServeFiles := response.GetHeaders(), false, false
+				headers = response.GetHeaders()
 				for key, value := range headers {
 					if key == StrongRedirectHeaderKey {
 						hasRedirect = true
@@ -198,13 +230,15 @@ func (app *application) handleHttpRequest(w http.ResponseWriter, c *Context) {
 					}
 					w.Header().Set(key, value)
 				}
-				if hasRedirect {
-					http.Redirect(w, c.Request, headers[StrongRedirectHeaderKey], response.GetStatus())
-					return
-				} else if hasServeFiles {
-					http.ServeFile(w, c.Request, headers[ServeFileHeaderKey])
-					return
-				}
+			}
+			// Многозначные заголовки добавляются после заменяющих
+			writeMultiHeaders(w, c, response)
+			if hasRedirect {
+				http.Redirect(w, c.Request, headers[StrongRedirectHeaderKey], response.GetStatus())
+				return
+			} else if hasServeFiles {
+				http.ServeFile(w, c.Request, headers[ServeFileHeaderKey])
+				return
 			}
 			w.WriteHeader(response.GetStatus())
 			w.Write(response.GetData())
@@ -229,53 +263,121 @@ func (app *application) LocalDo(req *http.Request) IResponse {
 	c.Request, c.IsFrozenRequestBody = req, true
 	c.isLocalRequest = true
 
-	httpMethod, path := c.Request.Method, c.Request.URL.Path
-	if app.checkMethodForHaveBody(httpMethod) && c.Request.Body != nil {
-		if b, _ := ioutil.ReadAll(c.Request.Body); len(b) > 0 {
-			c.Request.Body.Close()
-			c.Request.Body = ioutil.NopCloser(bytes.NewReader(b))
-		}
-	}
+	defer c.releaseBody()
+	defer c.releaseContext()
 	defer func() {
 		if rvr := recover(); rvr != nil {
 			fmt.Fprintf(os.Stderr, "Panic: %+v\n", rvr)
 			debug.PrintStack()
 		}
 	}()
-	response, _ := app.handleRouter(&app.Router, httpMethod, path, c)
+	response, _ := app.handleRouter(c)
+	if r, ok := response.(*Response); ok && len(c.responseHeader) > 0 {
+		header := c.responseHeader
+		addHeaderValues(header, r.MultiHeaders)
+		r.MultiHeaders = header
+	}
 	return response
 }
 
-func (app *application) handleRouter(router *Router, httpMethod, path string, c *Context) (IResponse, bool) {
-	if router != nil {
-		// Поиск роута
-		if router.routes != nil && len(router.routes) > 0 {
-			if routes, ok := router.routes[httpMethod]; ok && len(routes) > 0 {
-				for _, route := range routes {
-					if params, ok := route.CheckPath(path); ok {
-						return c.resetRoute(route, params).nextHandler()
-					}
-				}
-			}
+// Adding the headers of the context (cookies) and the multi-valued headers of the response.
+func writeMultiHeaders(w http.ResponseWriter, c *Context, response IResponse) {
+	addHeaderValues(w.Header(), c.responseHeader)
+	if r, ok := response.(IMultiHeadersResponse); ok {
+		addHeaderValues(w.Header(), r.GetMultiHeaders())
+	}
+}
+
+func (app *application) handleRouter(c *Context) (IResponse, bool) {
+	// Путь со слешем в конце перенаправляется на путь роута без слеша
+	if p := c.Request.URL.Path; app.redirectTrailingSlash && len(p) > 1 && p[len(p)-1] == '/' {
+		// Для catch-all роутов (Static, Mount) слеш в конце значим
+		if route, _ := app.Router.findRoute(c.Request); route != nil && !route.endsWithCatchAll() {
+			return app.redirectToPath(c, normalizeRequestPath(p), strings.TrimSuffix(c.Request.URL.RawPath, "/")), true
 		}
-		// Поиск следующего роутера
-		if router.groups != nil && len(router.groups) > 0 {
-			for _, r := range router.groups {
-				if strings.LastIndexByte(r.basePath, '}') >= 0 && r.rxPath != nil {
-					if _, ok := r.CheckPath(path); ok {
-						return app.handleRouter(r, httpMethod, path, c)
-					}
-					continue
-				}
-				if strings.Index(path, r.basePath) >= 0 {
-					return app.handleRouter(r, httpMethod, path, c)
-				}
+	}
+	// Поиск роута в дереве
+	if route, params := app.Router.findRoute(c.Request); route != nil {
+		// Для роутов версий тело запроса подготавливается диспетчером версий
+		if !route.dispatcher {
+			if res := app.prepareRequestBody(c, route); res != nil {
+				return res, true
 			}
 		}
+		return c.resetRoute(route, params).nextHandler()
 	}
 	return nil, false
 }
 
+// Processing of the request without the route: the path is registered by other methods - 405 (or the automatic OPTIONS response), otherwise 404.
+func (app *application) handleNoRoute(c *Context, httpMethod, path string) IResponse {
+	if app.redirectFixedPath {
+		if fixed, ok := app.Router.fixedPath(c.Request, app.caseInsensitivePath); ok && fixed != path {
+			return app.redirectToPath(c, fixed, "")
+		}
+	}
+	// Путь и метод зарегистрированы, но запрос не подошел по Content-Type или Accept
+	switch app.Router.mismatchStatus(c.Request) {
+	case http.StatusUnsupportedMediaType:
+		return c.Serializer().Response(415, NewError("415", c.Trans("Unsupported media type")).SetMetadata(H{
+			"method":       c.Request.Method,
+			"path":         c.Request.URL.Path,
+			"content_type": c.ContentType(),
+		}))
+	case http.StatusNotAcceptable:
+		return c.Serializer().Response(406, NewError("406", c.Trans("Not acceptable")).SetMetadata(H{
+			"method": c.Request.Method,
+			"path":   c.Request.URL.Path,
+			"accept": c.MustRequestHeader("Accept"),
+		}))
+	}
+	allowedMethods := app.Router.allowedMethods(c.Request.Host, path)
+	if len(allowedMethods) < 1 || hasString(allowedMethods, httpMethod) {
+		return app.noRouteHandler(c)
+	}
+	if !hasString(allowedMethods, http.MethodOptions) {
+		allowedMethods = append(allowedMethods, http.MethodOptions)
+	}
+	c.allowedMethods = allowedMethods
+	if httpMethod == http.MethodOptions {
+		return &Response{
+			Status:  http.StatusNoContent,
+			Headers: map[string]string{"Allow": strings.Join(allowedMethods, ", ")},
+		}
+	}
+	response := app.methodNotAllowedHandler(c)
+	if response != nil {
+		if headers := response.GetHeaders(); headers != nil {
+			headers["Allow"] = strings.Join(allowedMethods, ", ")
+		}
+	}
+	return response
+}
+
+// Path of the redirect starting with a single slash, //host and /\host are treated by browsers as other host.
+func safeRedirectPath(p string) string {
+	i := 0
+	for i < len(p) && (p[i] == '/' || p[i] == '\\') {
+		i++
+	}
+	return "/" + p[i:]
+}
+
+// Redirect to the canonical path of the route with the query of the request:
+// 301 for GET and HEAD, otherwise 308 (the method and the body are preserved).
+// The path is escaped again (%3F is not the query), rawPath keeps the original encoding (%2F) if it matches the path.
+func (app *application) redirectToPath(c *Context, p, rawPath string) IResponse {
+	status := http.StatusMovedPermanently
+	if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+		status = http.StatusPermanentRedirect
+	}
+	p = (&url.URL{Path: safeRedirectPath(p), RawPath: rawPath}).EscapedPath()
+	if len(c.Request.URL.RawQuery) > 0 {
+		p += "?" + c.Request.URL.RawQuery
+	}
+	return RedirectResponse(status, p)
+}
+
 func (app *application) Run(address string) error {
 	app.printWelcomeMessage(address, false)
 	return http.ListenAndServe(address, app)
@@ -300,6 +402,59 @@ func (app *application) SetNoImplementedHandler(handler HandlerFunc) IApplicatio
 	return app
 }
 
+// Register the type of the route parameters (see just.RegisterParamType).
+func (app *application) RegisterParamType(name, pattern string, converter ParamConverter) IApplication {
+	RegisterParamType(name, pattern, converter)
+	return app
+}
+
+// Redirect requests with a trailing slash to the route path without it (by default the slash is ignored).
+func (app *application) SetRedirectTrailingSlash(enable bool) IApplication {
+	app.redirectTrailingSlash = enable
+	return app
+}
+
+// Redirect requests without the route to the cleaned path (duplicate slashes, "." and ".." segments)
+// of the registered route, caseInsensitive - plain text segments of the path are compared ignoring case.
+func (app *application) SetRedirectFixedPath(enable, caseInsensitive bool) IApplication {
+	app.redirectFixedPath, app.caseInsensitivePath = enable, caseInsensitive
+	return app
+}
+
+// Collect route conflicts (duplicates, shadowed and ambiguous routes) as errors available by RouteConflicts,
+// otherwise the conflict causes panic in debug mode and warning in release mode.
+func (app *application) SetRouteConflictsAsErrors(enable bool) IApplication {
+	app.Router.collectConflicts = enable
+	return app
+}
+
+// Set the options of the request body of all routes without own options (see BodyOptions).
+func (app *application) SetDefaultBodyOptions(options BodyOptions) IApplication {
+	app.Router.mutex.Lock()
+	defer app.Router.mutex.Unlock()
+	app.Router.bodyOptions = &options
+	return app
+}
+
+func (app *application) RouteConflicts() []error {
+	app.Router.mutex.RLock()
+	defer app.Router.mutex.RUnlock()
+	return append([]error(nil), app.Router.conflicts...)
+}
+
+func (app *application) SetMethodNotAllowedHandler(handler HandlerFunc) IApplication {
+	app.methodNotAllowedHandler = handler
+	return app
+}
+
+// Response of the application when no route found for the request.
+func noRouteResponse(c *Context) IResponse {
+	if app, ok := c.app.(*application); ok && app.noRouteHandler != nil {
+		return app.noRouteHandler(c)
+	}
+	return noRouteDefHandler(c)
+}
+
 func noRouteDefHandler(c *Context) IResponse {
 	return c.Serializer().Response(404,
 		NewError("404", c.Trans("Route not found")).SetMetadata(H{
@@ -308,6 +463,15 @@ func noRouteDefHandler(c *Context) IResponse {
 		}))
 }
 
+func methodNotAllowedDefHandler(c *Context) IResponse {
+	return c.Serializer().Response(405,
+		NewError("405", c.Trans("Method not allowed")).SetMetadata(H{
+			"method": c.Request.Method,
+			"path":   c.Request.URL.Path,
+			"allow":  c.AllowedMethods(),
+		}))
+}
+
 func noImplementedDefHandler(c *Context) IResponse {
 	meta := H{
 		"method": c.Request.Method,
@@ -320,6 +484,23 @@ func noImplementedDefHandler(c *Context) IResponse {
 		NewError("501", c.Trans("Response not implemented for current Route")).SetMetadata(meta))
 }
 
+// Function "url" for templates, parameters are passed in pairs.
+// `{{ url "user" "id" 12 }}`
+func (app *application) templateURLFor(name string, pairs ...interface{}) (string, error) {
+	if len(pairs)%2 != 0 {
+		return "", errors.New("odd number of route params")
+	}
+	params := make(H, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		key, ok := pairs[i].(string)
+		if !ok {
+			return "", errors.New("route param name must be a string")
+		}
+		params[key] = pairs[i+1]
+	}
+	return app.URLFor(name, params)
+}
+
 func (app *application) initPool() *application {
 	app.pool.New = func() interface{} {
 		return &Context{app: app}
@@ -358,9 +539,13 @@ func NewClear() IApplication {
 			groups:          nil,
 			routes:          nil,
 		},
-		noRouteHandler:       noRouteDefHandler,
-		noImplementedHandler: noImplementedDefHandler,
-		translator:           &baseTranslator{defaultLocale: "en"},
+		noRouteHandler:          noRouteDefHandler,
+		noImplementedHandler:    noImplementedDefHandler,
+		methodNotAllowedHandler: methodNotAllowedDefHandler,
+		translator:              &baseTranslator{defaultLocale: "en"},
+	}
+	app.templatingManager.funcMap = map[string]interface{}{
+		"url": app.templateURLFor,
 	}
 	return app.initPool()
 }
@@ -0,0 +1,333 @@
+package just
+
+import (
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Matcher of the request for the route, checked after the path and the method.
+type IRouteMatcher interface {
+	MatchRequest(req *http.Request) bool // Checking the request.
+	MismatchStatus() int                 // HTTP status when no route matched the request (404, 406, 415).
+	String() string                      // Description of the matcher.
+}
+
+type headerMatcher struct {
+	key   string
+	value string
+	rx    *regexp.Regexp
+}
+
+func (m *headerMatcher) MatchRequest(req *http.Request) bool {
+	values, ok := req.Header[m.key]
+	if !ok || len(values) < 1 {
+		return false
+	}
+	if m.rx != nil {
+		return m.rx.MatchString(values[0])
+	}
+	return len(m.value) < 1 || values[0] == m.value
+}
+
+func (m *headerMatcher) MismatchStatus() int {
+	return http.StatusNotFound
+}
+
+func (m *headerMatcher) String() string {
+	if m.rx != nil {
+		return "header " + m.key + " ~ " + m.rx.String()
+	}
+	if len(m.value) < 1 {
+		return "header " + m.key
+	}
+	return "header " + m.key + " = " + m.value
+}
+
+// The request header is equal to the value (empty value - the header is present).
+// `app.GET("/users", handlerV2).Match(just.MatchHeader("X-Api-Version", "2"))`
+func MatchHeader(key, value string) IRouteMatcher {
+	return &headerMatcher{key: http.CanonicalHeaderKey(key), value: value}
+}
+
+// The request header matches the regular expression.
+func MatchHeaderRegexp(key, pattern string) IRouteMatcher {
+	return &headerMatcher{key: http.CanonicalHeaderKey(key), rx: regexp.MustCompile(pattern)}
+}
+
+type queryMatcher struct {
+	key string
+}
+
+func (m *queryMatcher) MatchRequest(req *http.Request) bool {
+	if req.URL == nil {
+		return false
+	}
+	_, ok := req.URL.Query()[m.key]
+	return ok
+}
+
+func (m *queryMatcher) MismatchStatus() int {
+	return http.StatusNotFound
+}
+
+func (m *queryMatcher) String() string {
+	return "query " + m.key
+}
+
+// The query param is present in the request.
+func MatchQuery(key string) IRouteMatcher {
+	return &queryMatcher{key: key}
+}
+
+type contentTypeMatcher struct {
+	types []string
+}
+
+func (m *contentTypeMatcher) MatchRequest(req *http.Request) bool {
+	contentType := mediaTypeOf(req.Header.Get(ContentTypeHeaderKey))
+	for _, t := range m.types {
+		if mediaTypeMatches(t, contentType) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *contentTypeMatcher) MismatchStatus() int {
+	return http.StatusUnsupportedMediaType
+}
+
+func (m *contentTypeMatcher) String() string {
+	return "content-type " + strings.Join(m.types, ", ")
+}
+
+// Content-Type of the request is one of the types (wildcards are supported: image/*).
+func MatchContentType(types ...string) IRouteMatcher {
+	m := &contentTypeMatcher{types: make([]string, len(types))}
+	for i, t := range types {
+		m.types[i] = mediaTypeOf(t)
+	}
+	return m
+}
+
+type acceptMatcher struct {
+	types []string
+}
+
+func (m *acceptMatcher) MatchRequest(req *http.Request) bool {
+	for _, r := range parseAcceptHeader(req.Header.Get("Accept")) {
+		for _, t := range m.types {
+			if mediaTypeMatches(r.mediaType, t) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (m *acceptMatcher) MismatchStatus() int {
+	return http.StatusNotAcceptable
+}
+
+func (m *acceptMatcher) String() string {
+	return "accept " + strings.Join(m.types, ", ")
+}
+
+// One of the types is acceptable by the Accept header of the request (without the header any type is acceptable).
+func MatchAccept(types ...string) IRouteMatcher {
+	m := &acceptMatcher{types: make([]string, len(types))}
+	for i, t := range types {
+		m.types[i] = mediaTypeOf(t)
+	}
+	return m
+}
+
+// Media range of the Accept header.
+type acceptRange struct {
+	mediaType string
+	q         float64
+}
+
+// Media type without parameters in lower case.
+func mediaTypeOf(value string) string {
+	if i := strings.IndexByte(value, ';'); i >= 0 {
+		value = value[:i]
+	}
+	return strings.ToLower(strings.TrimSpace(value))
+}
+
+// Checking the media type by the pattern with wildcards (*/*, application/*).
+func mediaTypeMatches(pattern, mediaType string) bool {
+	if pattern == "*/*" || pattern == mediaType {
+		return true
+	}
+	if strings.HasSuffix(pattern, "/*") {
+		return strings.HasPrefix(mediaType, pattern[:len(pattern)-1])
+	}
+	return false
+}
+
+// Parsing of the Accept header, ranges are sorted by quality (ranges with q=0 are excluded).
+// Without the header any type is acceptable.
+func parseAcceptHeader(value string) []acceptRange {
+	all := parseAcceptRanges(value)
+	ranges := all[:0]
+	for _, r := range all {
+		if r.q > 0 {
+			ranges = append(ranges, r)
+		}
+	}
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return ranges[i].q > ranges[j].q
+	})
+	return ranges
+}
+
+// Checking the request by all matchers of the route.
+func (r *Router) matchRequest(req *http.Request) bool {
+	for _, m := range r.matchers {
+		if !m.MatchRequest(req) {
+			return false
+		}
+	}
+	return true
+}
+
+// Add matchers of the request to the last registered routes.
+// Routes with matchers are checked before routes without them, if the request does not match any route
+// the status of the matchers is returned (415 - Content-Type, 406 - Accept).
+// `app.POST("/upload", handler).Match(just.MatchContentType("multipart/form-data"))`
+func (r *Router) Match(matchers ...IRouteMatcher) IRoute {
+	r.match(nil, matchers)
+	return r
+}
+
+func (r *Router) match(targets []*Router, matchers []IRouteMatcher) {
+	root := r.rootRouter()
+	root.mutex.Lock()
+	defer root.mutex.Unlock()
+	if targets == nil {
+		targets = r.lastRoutes
+	}
+	before := r.routesConflicts(targets)
+	for _, route := range targets {
+		route.matchers = append(route.matchers, matchers...)
+	}
+	// Совпадение роутов зависит от матчеров
+	r.recheckRoutes(targets, before)
+}
+
+// Selection of the route of the node by the matchers of the request (nil request - without checking).
+func (n *routeNode) selectRoute(httpMethod string, req *http.Request) *Router {
+	routes := n.routes[httpMethod]
+	if req == nil {
+		if len(routes) > 0 {
+			return routes[0]
+		}
+		return nil
+	}
+	for _, route := range routes {
+		if len(route.matchers) > 0 && route.matchRequest(req) {
+			return route
+		}
+	}
+	for _, route := range routes {
+		if len(route.matchers) < 1 {
+			return route
+		}
+	}
+	return nil
+}
+
+// Status when the request does not match the matchers of the routes:
+// 406 - a route differs only by Accept, 415 - by Content-Type, otherwise 404.
+func (n *routeNode) mismatchStatus(httpMethod string, req *http.Request) int {
+	status := http.StatusNotFound
+	for _, route := range n.routes[httpMethod] {
+		only406, has415 := true, false
+		for _, m := range route.matchers {
+			if !m.MatchRequest(req) {
+				switch m.MismatchStatus() {
+				case http.StatusNotAcceptable:
+				case http.StatusUnsupportedMediaType:
+					has415, only406 = true, false
+				default:
+					only406 = false
+				}
+			}
+		}
+		if only406 {
+			return http.StatusNotAcceptable
+		}
+		if has415 {
+			status = http.StatusUnsupportedMediaType
+		}
+	}
+	return status
+}
+
+// Parsing of all ranges of the Accept header in order of the header (including ranges with q=0).
+func parseAcceptRanges(value string) []acceptRange {
+	if len(strings.TrimSpace(value)) < 1 {
+		return []acceptRange{{mediaType: "*/*", q: 1}}
+	}
+	ranges := make([]acceptRange, 0, strings.Count(value, ",")+1)
+	for _, part := range strings.Split(value, ",") {
+		r := acceptRange{mediaType: mediaTypeOf(part), q: 1}
+		if len(r.mediaType) < 1 {
+			continue
+		}
+		if i := strings.IndexByte(part, ';'); i >= 0 {
+			for _, param := range strings.Split(part[i+1:], ";") {
+				if kv := strings.SplitN(strings.TrimSpace(param), "=", 2); len(kv) == 2 && strings.ToLower(strings.TrimSpace(kv[0])) == "q" {
+					if q, err := strconv.ParseFloat(strings.TrimSpace(kv[1]), 64); err == nil {
+						r.q = q
+					}
+				}
+			}
+		}
+		ranges = append(ranges, r)
+	}
+	return ranges
+}
+
+// Document formats with the structured syntax suffix, which are not the data of the suffix (xhtml is not the xml data).
+var documentMediaTypes = map[string]bool{
+	"application/xhtml+xml": true,
+	"image/svg+xml":         true,
+}
+
+// Media type of the structured syntax suffix (application/problem+json -> application/json), empty - without suffix.
+func suffixMediaType(mediaType string) string {
+	slash, plus := strings.IndexByte(mediaType, '/'), strings.LastIndexByte(mediaType, '+')
+	if slash < 0 || plus <= slash+1 || plus == len(mediaType)-1 || documentMediaTypes[mediaType] {
+		return ""
+	}
+	return mediaType[:slash+1] + mediaType[plus+1:]
+}
+
+// Quality of the media type by the most specific range of the Accept header (RFC 7231, section 5.3.2),
+// ranges with the structured syntax suffix (application/vnd.api+json) match the media type of the suffix.
+func acceptQuality(ranges []acceptRange, mediaType string) float64 {
+	q, specificity := 0.0, -1
+	for _, r := range ranges {
+		s := -1
+		switch {
+		case r.mediaType == mediaType:
+			s = 3
+		case suffixMediaType(r.mediaType) == mediaType:
+			s = 2
+		case r.mediaType != "*/*" && mediaTypeMatches(r.mediaType, mediaType):
+			s = 1
+		case r.mediaType == "*/*":
+			s = 0
+		}
+		if s > specificity {
+			q, specificity = r.q, s
+		}
+	}
+	return q
+}
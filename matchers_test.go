@@ -0,0 +1,48 @@
+package just
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRouteMatchers(t *testing.T) {
+	app := New()
+	handler := func(name string) HandlerFunc {
+		return func(c *Context) IResponse { return &Response{Status: 200, Bytes: []byte(name)} }
+	}
+	app.POST("/upload", handler("json")).Match(MatchContentType("application/json"))
+	app.POST("/upload", handler("multipart")).Match(MatchContentType("multipart/form-data"))
+	app.GET("/report", handler("csv")).Match(MatchAccept("text/csv"))
+	app.GET("/report", handler("json")).Match(MatchAccept("application/json"))
+	app.GET("/users", handler("v2")).Match(MatchHeader("X-Api-Version", "2"))
+	app.GET("/users", handler("v1"))
+	app.GET("/search", handler("search")).Match(MatchQuery("q"))
+
+	tests := []struct {
+		method, path string
+		headers      map[string]string
+		status       int
+		body         string
+	}{
+		{"POST", "/upload", map[string]string{"Content-Type": "application/json; charset=utf-8"}, 200, "json"},
+		{"POST", "/upload", map[string]string{"Content-Type": "multipart/form-data; boundary=x"}, 200, "multipart"},
+		{"POST", "/upload", map[string]string{"Content-Type": "text/plain"}, 415, ""},
+		{"GET", "/report", map[string]string{"Accept": "text/csv;q=0.9, application/json;q=0.1"}, 200, "csv"},
+		{"GET", "/report", map[string]string{"Accept": "application/*"}, 200, "json"},
+		{"GET", "/report", map[string]string{"Accept": "text/html"}, 406, ""},
+		{"GET", "/users", map[string]string{"X-Api-Version": "2"}, 200, "v2"},
+		{"GET", "/users", nil, 200, "v1"},
+		{"GET", "/search?q=go", nil, 200, "search"},
+		{"GET", "/search", nil, 404, ""},
+	}
+	for _, test := range tests {
+		w, req := httptest.NewRecorder(), httptest.NewRequest(test.method, test.path, nil)
+		for key, value := range test.headers {
+			req.Header.Set(key, value)
+		}
+		app.ServeHTTP(w, req)
+		if w.Code != test.status || (len(test.body) > 0 && w.Body.String() != test.body) {
+			t.Errorf("%s %s %v: expected %d %q, got %d %q", test.method, test.path, test.headers, test.status, test.body, w.Code, w.Body.String())
+		}
+	}
+}
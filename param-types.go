@@ -0,0 +1,162 @@
+package just
+
+import (
+	"errors"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Errors
+var (
+	ErrParamNotFound     = errors.New("route param not found")
+	ErrInvalidParamPtr   = errors.New("destination must be a non-nil pointer")
+	ErrParamTypeMismatch = errors.New("route param value can not be assigned to destination")
+)
+
+// Converter of the route parameter value to the typed value.
+type ParamConverter func(value string) (interface{}, error)
+
+// Type of the route parameters.
+type paramType struct {
+	pattern   string         // Regular expression of the value.
+	rx        *regexp.Regexp // Regular expression to check the whole value.
+	isPath    bool           // The value can contain slashes.
+	converter ParamConverter // Converter of the value (nil - string).
+}
+
+// Types of the route parameters, shared by the routing and the validator.
+var (
+	paramTypesMutex = sync.RWMutex{}
+	paramTypes      = make(map[string]*paramType)
+)
+
+// Names of the validator rules and the param types with arguments (regexp(...), enum(...)), which can not be registered.
+var reservedParamTypes = map[string]bool{"rgx": true, "regexp": true, "enum": true}
+
+func convertIntParam(value string) (interface{}, error) {
+	return strconv.ParseInt(value, 10, 64)
+}
+
+func convertFloatParam(value string) (interface{}, error) {
+	return strconv.ParseFloat(value, 64)
+}
+
+func convertBoolParam(value string) (interface{}, error) {
+	return strconv.ParseBool(strings.ToLower(value))
+}
+
+func registerParamType(names []string, pattern string, isPath bool, converter ParamConverter) {
+	t := &paramType{
+		pattern:   pattern,
+		rx:        regexp.MustCompile("^" + pattern + "$"),
+		isPath:    isPath,
+		converter: converter,
+	}
+	paramTypesMutex.Lock()
+	defer paramTypesMutex.Unlock()
+	for _, name := range names {
+		if reservedParamTypes[name] {
+			panic("route param type [" + name + "] already registered")
+		}
+		if registered, ok := paramTypes[name]; ok {
+			// Повторная регистрация с тем же шаблоном (приложения, MountApp, тесты) не меняет тип
+			if registered.pattern == t.pattern && registered.isPath == t.isPath {
+				return
+			}
+			panic("route param type [" + name + "] already registered with other pattern")
+		}
+	}
+	for _, name := range names {
+		paramTypes[name] = t
+	}
+}
+
+// Register the type of the route parameters (slug, date, ulid, ...).
+// The type is available in the routes registered after it (`/{id:objectid}`), in the validator (`valid:"objectid"`) and in Context.ParamAs by the converter (nil - string value).
+// Types are shared by all applications, the pattern is checked when registering,
+// registration of the already registered type with the same pattern is ignored (the first converter is kept),
+// redefinition of the built-in type or of the type with other pattern causes panic.
+// `just.RegisterParamType("slug", "[a-z0-9]+(?:-[a-z0-9]+)*", nil)`
+func RegisterParamType(name, pattern string, converter ParamConverter) {
+	name = strings.TrimSpace(name)
+	if len(name) < 1 || strings.ContainsAny(name, "(){}:;/") {
+		panic("route param type [" + name + "] not valid")
+	}
+	if len(pattern) < 1 {
+		panic("pattern of route param type [" + name + "] is empty")
+	}
+	registerParamType([]string{name}, "(?:"+pattern+")", false, converter)
+}
+
+func lookupParamType(name string) (*paramType, bool) {
+	paramTypesMutex.RLock()
+	defer paramTypesMutex.RUnlock()
+	t, ok := paramTypes[name]
+	return t, ok
+}
+
+// Assign the value of the parameter to the destination pointer.
+func assignParamValue(ptr interface{}, value interface{}) error {
+	dst := reflect.ValueOf(ptr)
+	if dst.Kind() != reflect.Ptr || dst.IsNil() {
+		return ErrInvalidParamPtr
+	}
+	dst = dst.Elem()
+	src := reflect.ValueOf(value)
+	if src.Type().AssignableTo(dst.Type()) {
+		dst.Set(src)
+		return nil
+	}
+	if str, ok := value.(string); ok {
+		if err := setWithProperType(dst.Kind(), str, dst); err != ErrUnknownType {
+			return err
+		}
+		return ErrParamTypeMismatch
+	}
+	switch src.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		switch dst.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if dst.OverflowInt(src.Int()) {
+				return strconv.ErrRange
+			}
+			dst.SetInt(src.Int())
+			return nil
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		switch dst.Kind() {
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			if dst.OverflowUint(src.Uint()) {
+				return strconv.ErrRange
+			}
+			dst.SetUint(src.Uint())
+			return nil
+		}
+	case reflect.Float32, reflect.Float64:
+		switch dst.Kind() {
+		case reflect.Float32, reflect.Float64:
+			dst.SetFloat(src.Float())
+			return nil
+		}
+	}
+	if src.Type().ConvertibleTo(dst.Type()) && src.Kind() == dst.Kind() {
+		dst.Set(src.Convert(dst.Type()))
+		return nil
+	}
+	return ErrParamTypeMismatch
+}
+
+func init() {
+	registerParamType([]string{"s", "str", "string"}, patternParamString, false, nil)
+	registerParamType([]string{"p", "path"}, patternParamPath, true, nil)
+	registerParamType([]string{"hex"}, patternHex, false, nil)
+	registerParamType([]string{"rid"}, patternParamRID, false, nil)
+	registerParamType([]string{"uuid"}, patternParamUUID, false, nil)
+	registerParamType([]string{"i", "int", "integer"}, patternParamInteger, false, convertIntParam)
+	registerParamType([]string{"f", "number", "float"}, patternParamFloat, false, convertFloatParam)
+	registerParamType([]string{"b", "bool", "boolean"}, patternParamBoolean, false, convertBoolParam)
+	registerParamType([]string{"f.e", "file.ext"}, patternParamFileExt, false, nil)
+}
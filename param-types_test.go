@@ -0,0 +1,92 @@
+package just
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRegisterParamType(t *testing.T) {
+	app := New()
+	app.RegisterParamType("date", "\\d{4}-\\d{2}-\\d{2}", func(value string) (interface{}, error) {
+		return time.Parse("2006-01-02", value)
+	})
+	app.RegisterParamType("slug", "[a-z0-9]+(?:-[a-z0-9]+)*", nil)
+
+	var (
+		day  time.Time
+		slug string
+		id   int32
+		err  error
+	)
+	app.GET("/posts/{day:date}/{slug:slug}/{id:int}", func(c *Context) IResponse {
+		if err = c.ParamAs("day", &day); err == nil {
+			if err = c.ParamAs("slug", &slug); err == nil {
+				err = c.ParamAs("id", &id)
+			}
+		}
+		return &Response{Status: 200}
+	})
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/posts/2018-07-01/hello-world/12", nil))
+	if w.Code != 200 || err != nil {
+		t.Fatalf("unexpected result %d, %v", w.Code, err)
+	}
+	if !day.Equal(time.Date(2018, 7, 1, 0, 0, 0, 0, time.UTC)) || slug != "hello-world" || id != 12 {
+		t.Errorf("unexpected values %v %s %d", day, slug, id)
+	}
+
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/posts/2018-07/Hello/12", nil))
+	if w.Code != 404 {
+		t.Errorf("expected status 404, got %d", w.Code)
+	}
+
+	if list := Validation(&struct {
+		Slug string `valid:"slug"`
+	}{"Not a slug"}); len(list) != 1 {
+		t.Errorf("expected validation error by registered type, got %v", list)
+	}
+
+	// Встроенные и уже зарегистрированные типы не переопределяются
+	for _, name := range []string{"int", "uuid", "regexp", "slug"} {
+		func() {
+			defer func() {
+				if rvr := recover(); rvr == nil {
+					t.Errorf("expected panic on redefinition of the param type %q", name)
+				}
+			}()
+			app.RegisterParamType(name, ".+", nil)
+		}()
+	}
+	if pt, ok := lookupParamType("int"); !ok || pt.pattern != patternParamInteger {
+		t.Error("built-in param type int must not be redefined")
+	}
+	// Другое приложение регистрирует тот же тип
+	sub := New().RegisterParamType("slug", "[a-z0-9]+(?:-[a-z0-9]+)*", nil)
+	app.MountApp("/sub", sub)
+}
+
+func TestAssignParamValue(t *testing.T) {
+	var (
+		i8  int8
+		u   uint
+		f   float32
+		str string
+	)
+	if err := assignParamValue(&i8, int64(300)); err == nil {
+		t.Error("expected overflow error")
+	}
+	if err := assignParamValue(&u, "42"); err != nil || u != 42 {
+		t.Errorf("unexpected result %d, %v", u, err)
+	}
+	if err := assignParamValue(&f, 1.5); err != nil || f != 1.5 {
+		t.Errorf("unexpected result %f, %v", f, err)
+	}
+	if err := assignParamValue(&str, int64(1)); err != ErrParamTypeMismatch {
+		t.Errorf("expected type mismatch error, got %v", err)
+	}
+	if err := assignParamValue(str, "value"); err != ErrInvalidParamPtr {
+		t.Errorf("expected invalid pointer error, got %v", err)
+	}
+}
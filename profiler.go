@@ -32,3 +32,15 @@ func (w *profiledResponseWriter) WriteHeader(status int) {
 func (w *profiledResponseWriter) Header() http.Header {
 	return w.writer.Header()
 }
+
+// Flushing of the buffered data to the client (streams and server-sent events).
+func (w *profiledResponseWriter) Flush() {
+	if flusher, ok := w.writer.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Original response writer (http.ResponseController).
+func (w *profiledResponseWriter) Unwrap() http.ResponseWriter {
+	return w.writer
+}
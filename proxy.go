@@ -0,0 +1,225 @@
+package just
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// Hop of the forwarding chain (element of the Forwarded header or the address of X-Forwarded-For).
+type forwardedHop struct {
+	forAddr string // Address of the client of the proxy (for).
+	host    string // Host requested by the client of the proxy.
+	proto   string // Protocol used by the client of the proxy.
+}
+
+// Request information resolved by the trusted proxies.
+type forwardedInfo struct {
+	clientIP string
+	host     string
+	proto    string
+}
+
+// Trusted proxies by default (loopback).
+var defaultTrustedProxies = mustParseProxies("127.0.0.0/8", "::1")
+
+func mustParseProxies(proxies ...string) []*net.IPNet {
+	networks := make([]*net.IPNet, 0, len(proxies))
+	for _, proxy := range proxies {
+		proxy = strings.TrimSpace(proxy)
+		if strings.IndexByte(proxy, '/') < 0 {
+			ip := net.ParseIP(proxy)
+			if ip == nil {
+				panic(fmt.Errorf("the trusted proxy [%s] not valid", proxy))
+			}
+			if ip.To4() != nil {
+				proxy += "/32"
+			} else {
+				proxy += "/128"
+			}
+		}
+		_, network, err := net.ParseCIDR(proxy)
+		if err != nil {
+			panic(fmt.Errorf("the trusted proxy [%s] not valid", proxy))
+		}
+		networks = append(networks, network)
+	}
+	return networks
+}
+
+// Set the trusted proxies by CIDR (10.0.0.0/8) or IP, forwarding headers (Forwarded, X-Forwarded-For, X-Real-Ip,
+// X-Forwarded-Proto, X-Forwarded-Host) are used only from the trusted proxies.
+// By default only loopback proxies are trusted, without arguments - no proxy is trusted,
+// all proxies are trusted only explicitly ("0.0.0.0/0", "::/0").
+func (app *application) SetTrustedProxies(proxies ...string) IApplication {
+	app.trustedProxies = mustParseProxies(proxies...)
+	return app
+}
+
+// Checking the address of the proxy by the trusted proxies of the application.
+func (app *application) IsTrustedProxy(ip string) bool {
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return false
+	}
+	networks := app.trustedProxies
+	if networks == nil {
+		networks = defaultTrustedProxies
+	}
+	for _, network := range networks {
+		if network.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// Value of the parameter of the Forwarded header without quotes.
+func unquoteForwardedValue(value string) string {
+	value = strings.TrimSpace(value)
+	if len(value) > 1 && value[0] == '"' && value[len(value)-1] == '"' {
+		value = strings.Replace(value[1:len(value)-1], `\"`, `"`, -1)
+	}
+	return value
+}
+
+// Parsing of the Forwarded header (RFC 7239): for=192.0.2.60;proto=http;host=example.com, for="[2001:db8::1]:4711".
+func parseForwardedHeader(values []string) []forwardedHop {
+	var hops []forwardedHop
+	for _, value := range values {
+		for _, element := range splitQuoted(value, ',') {
+			var hop forwardedHop
+			for _, pair := range splitQuoted(element, ';') {
+				kv := strings.SplitN(pair, "=", 2)
+				if len(kv) != 2 {
+					continue
+				}
+				switch strings.ToLower(strings.TrimSpace(kv[0])) {
+				case "for":
+					hop.forAddr = unquoteForwardedValue(kv[1])
+				case "host":
+					hop.host = unquoteForwardedValue(kv[1])
+				case "proto":
+					hop.proto = strings.ToLower(unquoteForwardedValue(kv[1]))
+				}
+			}
+			hops = append(hops, hop)
+		}
+	}
+	return hops
+}
+
+// Splitting of the value by the separator outside of the quoted strings.
+func splitQuoted(value string, sep byte) []string {
+	var result []string
+	quoted, start := false, 0
+	for i := 0; i < len(value); i++ {
+		switch value[i] {
+		case '\\':
+			if quoted {
+				i++
+			}
+		case '"':
+			quoted = !quoted
+		case sep:
+			if !quoted {
+				result = append(result, value[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(result, value[start:])
+}
+
+// IP of the address of the hop (192.0.2.43:47011, [2001:db8::1]:4711), empty - unknown or obfuscated address.
+func forwardedIP(addr string) string {
+	addr = strings.TrimSpace(addr)
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		addr = host
+	}
+	addr = strings.TrimSuffix(strings.TrimPrefix(addr, "["), "]")
+	if ip := net.ParseIP(addr); ip != nil {
+		return ip.String()
+	}
+	return ""
+}
+
+// First value of the list of the header (https, http -> https).
+func firstHeaderValue(value string) string {
+	if i := strings.IndexByte(value, ','); i >= 0 {
+		value = value[:i]
+	}
+	return strings.TrimSpace(value)
+}
+
+// Forwarding chain of the request: the Forwarded header, otherwise X-Forwarded-For (or X-Real-Ip)
+// with X-Forwarded-Host, X-Forwarded-Port and X-Forwarded-Proto (or X-Scheme) of the nearest proxy.
+func (c *Context) forwardingChain() []forwardedHop {
+	if values := c.Request.Header["Forwarded"]; len(values) > 0 {
+		return parseForwardedHeader(values)
+	}
+	var hops []forwardedHop
+	for _, value := range c.Request.Header["X-Forwarded-For"] {
+		for _, addr := range strings.Split(value, ",") {
+			if addr = strings.TrimSpace(addr); len(addr) > 0 {
+				hops = append(hops, forwardedHop{forAddr: addr})
+			}
+		}
+	}
+	if len(hops) < 1 {
+		if addr := strings.TrimSpace(c.MustRequestHeader("X-Real-Ip")); len(addr) > 0 {
+			hops = append(hops, forwardedHop{forAddr: addr})
+		}
+	}
+	host, proto := firstHeaderValue(c.MustRequestHeader("X-Forwarded-Host")), firstHeaderValue(c.MustRequestHeader("X-Forwarded-Proto"))
+	if len(proto) < 1 {
+		proto = firstHeaderValue(c.MustRequestHeader("X-Scheme"))
+	}
+	if port := firstHeaderValue(c.MustRequestHeader("X-Forwarded-Port")); len(host) > 0 && len(port) > 0 {
+		if _, _, err := net.SplitHostPort(host); err != nil && !(port == "80" && proto == "http") && !(port == "443" && proto == "https") {
+			host = net.JoinHostPort(strings.Trim(host, "[]"), port)
+		}
+	}
+	if len(host) > 0 || len(proto) > 0 {
+		if len(hops) < 1 {
+			hops = append(hops, forwardedHop{})
+		}
+		hops[len(hops)-1].host, hops[len(hops)-1].proto = host, strings.ToLower(proto)
+	}
+	return hops
+}
+
+// Resolving of the client address, the host and the protocol by the forwarding chain from right to left,
+// the walk stops at the first address that is not a trusted proxy.
+func (c *Context) forwarded() forwardedInfo {
+	var info forwardedInfo
+	if ip, _, err := net.SplitHostPort(strings.TrimSpace(c.Request.RemoteAddr)); err == nil {
+		info.clientIP = ip
+	}
+	if c.app == nil || !c.app.IsTrustedProxy(info.clientIP) {
+		return info
+	}
+	hops := c.forwardingChain()
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := hops[i]
+		if len(hop.host) > 0 {
+			info.host = hop.host
+		}
+		if len(hop.proto) > 0 {
+			info.proto = hop.proto
+		}
+		if len(hop.forAddr) < 1 {
+			continue
+		}
+		ip := forwardedIP(hop.forAddr)
+		if len(ip) < 1 {
+			// Неизвестный или скрытый адрес (unknown, _hidden) завершает цепочку
+			break
+		}
+		info.clientIP = ip
+		if !c.app.IsTrustedProxy(ip) {
+			break
+		}
+	}
+	return info
+}
@@ -0,0 +1,79 @@
+package just
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTrustedProxies(t *testing.T) {
+	app := New().SetTrustedProxies("10.0.0.0/8", "2001:db8::1")
+	tests := []struct {
+		remote  string
+		headers map[string]string
+		ip      string
+		scheme  string
+		host    string
+	}{
+		// Недоверенный клиент не может подменить адрес
+		{"203.0.113.5:1000", map[string]string{"X-Forwarded-For": "1.1.1.1", "X-Forwarded-Proto": "https"}, "203.0.113.5", "http", "example.com"},
+		{"10.0.0.1:1000", map[string]string{"X-Forwarded-For": "1.1.1.1, 198.51.100.7, 10.0.0.2", "X-Forwarded-Proto": "https", "X-Forwarded-Host": "api.example.com"}, "198.51.100.7", "https", "api.example.com"},
+		{"10.0.0.1:1000", map[string]string{"X-Real-Ip": "198.51.100.7", "X-Scheme": "https"}, "198.51.100.7", "https", "example.com"},
+		{"10.0.0.1:1000", map[string]string{"X-Forwarded-For": "198.51.100.7", "X-Forwarded-Proto": "https", "X-Forwarded-Host": "api.example.com", "X-Forwarded-Port": "8443"}, "198.51.100.7", "https", "api.example.com:8443"},
+		{"[2001:db8::1]:1000", map[string]string{"Forwarded": `for="[2001:db8::7]:4711";proto=https;host="shop.example.com", for=10.1.1.1`}, "2001:db8::7", "https", "shop.example.com"},
+		{"10.0.0.1:1000", map[string]string{"Forwarded": `for=192.0.2.60;proto=http, for=198.51.100.7;host=a.example.com;proto=https`, "X-Forwarded-For": "1.1.1.1"}, "198.51.100.7", "https", "a.example.com"},
+		{"10.0.0.1:1000", map[string]string{"Forwarded": `for=_hidden, for=10.2.2.2`}, "10.2.2.2", "http", "example.com"},
+	}
+	for _, test := range tests {
+		req := httptest.NewRequest("GET", "http://example.com/", nil)
+		req.RemoteAddr = test.remote
+		for key, value := range test.headers {
+			req.Header.Set(key, value)
+		}
+		c := &Context{app: app, Request: req}
+		if ip, scheme, host := c.ClientIP(true), c.RequestUrlScheme(), c.RequestHost(); ip != test.ip || scheme != test.scheme || host != test.host {
+			t.Errorf("%s %v: expected %s %s %s, got %s %s %s", test.remote, test.headers, test.ip, test.scheme, test.host, ip, scheme, host)
+		}
+		if ip := c.ClientIP(false); len(ip) < 1 {
+			t.Errorf("expected the remote address, got %q", ip)
+		}
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic on the invalid proxy")
+		}
+	}()
+	app.SetTrustedProxies("10.0.0.0/33")
+}
+
+func TestDefaultTrustedProxies(t *testing.T) {
+	app := New()
+	tests := []struct {
+		remote, ip, scheme string
+	}{
+		{"203.0.113.5:1000", "203.0.113.5", "http"},
+		{"127.0.0.1:1000", "198.51.100.7", "https"},
+		{"[::1]:1000", "198.51.100.7", "https"},
+	}
+	for _, test := range tests {
+		req := httptest.NewRequest("GET", "http://example.com/", nil)
+		req.RemoteAddr = test.remote
+		req.Header.Set("X-Forwarded-For", "198.51.100.7")
+		req.Header.Set("Forwarded", "for=198.51.100.7;proto=https;host=evil.com")
+		c := &Context{app: app, Request: req}
+		if ip, scheme := c.ClientIP(true), c.RequestUrlScheme(); ip != test.ip || scheme != test.scheme {
+			t.Errorf("%s: expected %s %s, got %s %s", test.remote, test.ip, test.scheme, ip, scheme)
+		}
+	}
+	app.SetTrustedProxies()
+	req := httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "127.0.0.1:1000"
+	req.Header.Set("X-Forwarded-For", "198.51.100.7")
+	if ip := (&Context{app: app, Request: req}).ClientIP(true); ip != "127.0.0.1" {
+		t.Errorf("expected no trusted proxies, got %s", ip)
+	}
+	app.SetTrustedProxies("0.0.0.0/0", "::/0")
+	req.RemoteAddr = "203.0.113.5:1000"
+	if ip := (&Context{app: app, Request: req}).ClientIP(true); ip != "198.51.100.7" {
+		t.Errorf("expected all trusted proxies, got %s", ip)
+	}
+}
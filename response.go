@@ -25,12 +25,20 @@ type IResponse interface {
 	GetStreamHandler() (http.HandlerFunc, bool)
 }
 
+// Response with multi-valued headers (several Set-Cookie, Link, Vary values).
+// The values are added to the response after the headers of GetHeaders.
+type IMultiHeadersResponse interface {
+	GetMultiHeaders() http.Header
+}
+
 // Base Response struct.
 type Response struct {
 	Status  int               // HTTP status (200, 201,...).
 	Bytes   []byte            // Data bytes.
 	Headers map[string]string // Response headers.
 	Stream  http.HandlerFunc  // Stream method, to support standard HTTP package, as well as to work with WS.
+
+	MultiHeaders http.Header // Multi-valued response headers (Set-Cookie, Link, Vary).
 }
 
 func (r Response) HasStreamHandler() bool {
@@ -73,6 +81,41 @@ func (r *Response) GetHeaders() map[string]string {
 	return r.Headers
 }
 
+func (r *Response) GetMultiHeaders() http.Header {
+	return r.MultiHeaders
+}
+
+// Add the value of the multi-valued header.
+func (r *Response) AddHeader(key, value string) *Response {
+	if r.MultiHeaders == nil {
+		r.MultiHeaders = make(http.Header)
+	}
+	r.MultiHeaders.Add(key, value)
+	return r
+}
+
+// Add the cookie to the response.
+func (r *Response) SetCookie(cookie *Cookie) *Response {
+	if value := cookie.String(); len(value) > 0 {
+		r.AddHeader("Set-Cookie", value)
+	}
+	return r
+}
+
+// Add the deleting cookie to the response (Max-Age=0).
+func (r *Response) ClearCookie(name, path, domain string) *Response {
+	return r.SetCookie(clearingCookie(name, path, domain))
+}
+
+// Adding the values of the headers (without replacing existing values).
+func addHeaderValues(dst, src http.Header) {
+	for key, values := range src {
+		for _, value := range values {
+			dst.Add(key, value)
+		}
+	}
+}
+
 // Create a stream response.
 func StreamResponse(handler http.HandlerFunc) IResponse {
 	return &Response{Bytes: nil, Status: -1, Headers: nil, Stream: handler}
@@ -0,0 +1,354 @@
+package just
+
+import (
+	"fmt"
+	"regexp/syntax"
+	"strings"
+)
+
+// Maximum number of sample values of the segment to check the overlap of the patterns.
+const maxSegmentSamples = 32
+
+// Kinds of the route conflicts.
+const (
+	RouteConflictDuplicate = "duplicate" // The same method and path are registered again.
+	RouteConflictShadowed  = "shadowed"  // The route is never selected, all its requests are processed by other route.
+	RouteConflictAmbiguous = "ambiguous" // The routes match the same requests, the selected route depends on the order of registration.
+	RouteConflictOverlap   = "overlap"   // Typed and untyped parameters match the same values, the route is selected by the type (only collected).
+)
+
+// Route conflict detected at registration.
+type RouteConflictError struct {
+	Kind         string // Kind of the conflict (duplicate, shadowed, ambiguous).
+	Method       string // HTTP method of the routes.
+	Path         string // Path of the route.
+	ConflictPath string // Path of the conflicting route registered earlier.
+	route        *Router
+}
+
+// Route conflict error text.
+func (e *RouteConflictError) Error() string {
+	switch e.Kind {
+	case RouteConflictDuplicate:
+		return "Route " + e.Method + " " + e.Path + " duplicates " + e.ConflictPath
+	case RouteConflictShadowed:
+		return "Route " + e.Method + " " + e.Path + " is shadowed by " + e.ConflictPath + " and will never be selected"
+	case RouteConflictOverlap:
+		return "Routes " + e.Method + " " + e.ConflictPath + " and " + e.Path + " overlap, the route is selected by the type of the parameter"
+	}
+	return "Routes " + e.Method + " " + e.ConflictPath + " and " + e.Path + " are ambiguous, the selected route depends on the order of registration"
+}
+
+// Relation of the sets of values matched by two segments.
+type segmentRelation uint8
+
+const (
+	relationEqual    segmentRelation = iota // The segments match the same values.
+	relationDisjoint                        // The segments have no common values.
+	relationSuperset                        // The first segment matches all values of the second.
+	relationSubset                          // The second segment matches all values of the first.
+	relationOverlap                         // The segments can match common values.
+)
+
+// Form of the segment without the names of the parameters.
+func (s *pathSegment) shape() string {
+	if s.kind == segmentStatic {
+		return s.raw
+	}
+	if s.matchesAny() {
+		return "{}"
+	}
+	return s.rx.String()
+}
+
+func compareSegments(a, b *pathSegment) segmentRelation {
+	switch {
+	case a.kind == segmentStatic && b.kind == segmentStatic:
+		if a.raw == b.raw {
+			return relationEqual
+		}
+		return relationDisjoint
+	case a.kind == segmentStatic:
+		if _, ok := b.match(a.raw, nil); ok {
+			return relationSubset
+		}
+		return relationDisjoint
+	case b.kind == segmentStatic:
+		if _, ok := a.match(b.raw, nil); ok {
+			return relationSuperset
+		}
+		return relationDisjoint
+	case a.shape() == b.shape():
+		return relationEqual
+	case a.kind == b.kind && a.matchesAny():
+		return relationSuperset
+	case a.kind == b.kind && b.matchesAny():
+		return relationSubset
+	case a.matchesAny() || b.matchesAny():
+		return relationOverlap
+	}
+	// Пересечение шаблонов проверяется на примерах значений
+	for _, sample := range a.samples() {
+		if _, ok := b.match(sample, nil); ok {
+			return relationOverlap
+		}
+	}
+	for _, sample := range b.samples() {
+		if _, ok := a.match(sample, nil); ok {
+			return relationOverlap
+		}
+	}
+	return relationDisjoint
+}
+
+// Sample values matched by the regular expression of the segment.
+func (s *pathSegment) samples() []string {
+	re, err := syntax.Parse(s.rx.String(), syntax.Perl)
+	if err != nil {
+		return nil
+	}
+	return regexpSamples(re.Simplify())
+}
+
+func regexpSamples(re *syntax.Regexp) []string {
+	switch re.Op {
+	case syntax.OpLiteral:
+		return []string{string(re.Rune)}
+	case syntax.OpCharClass:
+		var result []string
+		for i := 0; i+1 < len(re.Rune) && len(result) < 4; i += 2 {
+			result = append(result, string(re.Rune[i]))
+			if re.Rune[i+1] != re.Rune[i] {
+				result = append(result, string(re.Rune[i+1]))
+			}
+		}
+		return result
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		return []string{"a"}
+	case syntax.OpCapture:
+		return regexpSamples(re.Sub[0])
+	case syntax.OpStar, syntax.OpQuest:
+		return append([]string{""}, regexpSamples(re.Sub[0])...)
+	case syntax.OpPlus:
+		return regexpSamples(re.Sub[0])
+	case syntax.OpRepeat:
+		sub := regexpSamples(re.Sub[0])
+		result := make([]string, 0, len(sub)+1)
+		if re.Min < 1 {
+			result = append(result, "")
+		}
+		for _, sample := range sub {
+			result = append(result, strings.Repeat(sample, re.Min))
+		}
+		return result
+	case syntax.OpAlternate:
+		var result []string
+		for _, sub := range re.Sub {
+			result = append(result, regexpSamples(sub)...)
+		}
+		return limitSamples(result)
+	case syntax.OpConcat:
+		result := []string{""}
+		for _, sub := range re.Sub {
+			samples := regexpSamples(sub)
+			next := make([]string, 0, len(result)*len(samples))
+			for _, prefix := range result {
+				for _, sample := range samples {
+					next = append(next, prefix+sample)
+				}
+			}
+			result = limitSamples(next)
+		}
+		return result
+	}
+	return []string{""}
+}
+
+func limitSamples(samples []string) []string {
+	if len(samples) > maxSegmentSamples {
+		return samples[:maxSegmentSamples]
+	}
+	return samples
+}
+
+func sameMatchers(a, b *Router) bool {
+	if len(a.matchers) != len(b.matchers) {
+		return false
+	}
+	for i := range a.matchers {
+		if a.matchers[i].String() != b.matchers[i].String() {
+			return false
+		}
+	}
+	return true
+}
+
+// Conflict of the route with the route registered earlier (nil - no conflict), optional segments are compared in all variants of the paths.
+func routeConflict(route, earlier *Router) *RouteConflictError {
+	if route.method != earlier.method {
+		return nil
+	}
+	for _, segments := range segmentVariants(route.segments) {
+		for _, earlierSegments := range segmentVariants(earlier.segments) {
+			if e := segmentsConflict(route, earlier, segments, earlierSegments); e != nil {
+				return e
+			}
+		}
+	}
+	return nil
+}
+
+func segmentsConflict(route, earlier *Router, segments, earlierSegments []pathSegment) *RouteConflictError {
+	if len(segments) != len(earlierSegments) {
+		return nil
+	}
+	first := -1
+	relations := make([]segmentRelation, len(segments))
+	for i := range segments {
+		if relations[i] = compareSegments(&earlierSegments[i], &segments[i]); relations[i] == relationDisjoint {
+			return nil
+		}
+		if first < 0 && relations[i] != relationEqual {
+			first = i
+		}
+	}
+	e := &RouteConflictError{Method: route.method, Path: route.basePath, ConflictPath: earlier.basePath}
+	if first < 0 {
+		if !sameMatchers(route, earlier) {
+			return nil
+		}
+		e.Kind = RouteConflictDuplicate
+		return e
+	}
+	// Сегмент, на котором дерево выбирает между роутами
+	a, b := segmentPriority(&earlierSegments[first]), segmentPriority(&segments[first])
+	if a != b {
+		// Выбор определен типом сегмента, при несовпадении работает возврат к соседним узлам
+		e.Kind = RouteConflictOverlap
+		typed := a+b == 3 // Параметр с типом и параметр без типа
+		if a > b {
+			if typed {
+				return e
+			}
+			return nil
+		}
+		for _, rel := range relations[first:] {
+			if rel != relationEqual && rel != relationSuperset {
+				if typed {
+					return e
+				}
+				return nil
+			}
+		}
+		if len(earlier.matchers) > 0 {
+			return nil
+		}
+		e.Kind = RouteConflictShadowed
+		return e
+	}
+	for _, rel := range relations[first:] {
+		if rel != relationEqual && rel != relationSuperset {
+			e.Kind = RouteConflictAmbiguous
+			return e
+		}
+	}
+	if len(earlier.matchers) > 0 {
+		e.Kind = RouteConflictAmbiguous
+		return e
+	}
+	e.Kind = RouteConflictShadowed
+	return e
+}
+
+func (n *routeNode) eachRoute(fn func(route *Router)) {
+	for _, routes := range n.routes {
+		for _, route := range routes {
+			fn(route)
+		}
+	}
+	for _, c := range n.static {
+		c.eachRoute(fn)
+	}
+	for _, c := range n.params {
+		c.eachRoute(fn)
+	}
+	for _, c := range n.catchAll {
+		c.eachRoute(fn)
+	}
+}
+
+// Conflict of the route with other routes of its routing tree (nil - no conflict).
+func (r *Router) findConflict(route *Router) *RouteConflictError {
+	owner := route.parent.treeRouter()
+	if owner.tree == nil {
+		return nil
+	}
+	var conflict *RouteConflictError
+	owner.tree.eachRoute(func(earlier *Router) {
+		if earlier == route || (conflict != nil && conflict.Kind != RouteConflictOverlap) {
+			return
+		}
+		if e := routeConflict(route, earlier); e != nil && (conflict == nil || e.Kind != RouteConflictOverlap) {
+			conflict = e
+		}
+	})
+	if conflict != nil {
+		conflict.route = route
+		if len(owner.host) > 0 {
+			conflict.Path, conflict.ConflictPath = owner.host+conflict.Path, owner.host+conflict.ConflictPath
+		}
+	}
+	return conflict
+}
+
+// Report of the route conflict: collected as error, panic in debug mode, otherwise warning.
+func (r *Router) reportConflict(conflict *RouteConflictError) {
+	root := r.rootRouter()
+	if root.collectConflicts {
+		root.conflicts = append(root.conflicts, conflict)
+	} else if conflict.Kind == RouteConflictOverlap {
+		if IsDebug() {
+			fmt.Println("[DEBUG]", conflict.Error())
+		}
+	} else if IsDebug() {
+		panic(conflict)
+	} else {
+		fmt.Println("[WARNING]", conflict.Error())
+	}
+}
+
+// Checking the route at its registration.
+func (r *Router) checkRoute(route *Router) {
+	if conflict := r.findConflict(route); conflict != nil {
+		r.reportConflict(conflict)
+	}
+}
+
+// Checking the routes again after the change of their matchers (Match, Replace),
+// collected conflicts of the routes are replaced, other reported conflicts are not repeated.
+func (r *Router) recheckRoutes(routes []*Router, before []*RouteConflictError) {
+	root := r.rootRouter()
+	for i, route := range routes {
+		if root.collectConflicts {
+			conflicts := root.conflicts[:0]
+			for _, err := range root.conflicts {
+				if e, ok := err.(*RouteConflictError); !ok || e.route != route {
+					conflicts = append(conflicts, err)
+				}
+			}
+			root.conflicts = conflicts
+		} else if before[i] != nil {
+			continue
+		}
+		r.checkRoute(route)
+	}
+}
+
+// Conflicts of the routes before the change of their matchers.
+func (r *Router) routesConflicts(routes []*Router) []*RouteConflictError {
+	conflicts := make([]*RouteConflictError, len(routes))
+	for i, route := range routes {
+		conflicts[i] = r.findConflict(route)
+	}
+	return conflicts
+}
@@ -0,0 +1,155 @@
+package just
+
+import "strings"
+
+// Removing the routes selected by the function from the routing tables (without locking).
+func (r *Router) removeRoutes(fn func(route *Router) bool) bool {
+	root, removed := r.rootRouter(), false
+	table := root.table[:0]
+	for _, route := range root.table {
+		if !fn(route) {
+			table = append(table, route)
+			continue
+		}
+		removed = true
+		if routes, ok := route.parent.routes[route.method]; ok {
+			list := routes[:0]
+			for _, item := range routes {
+				if item != IRoute(route) {
+					list = append(list, item)
+				}
+			}
+			route.parent.routes[route.method] = list
+		}
+		if v := route.versionRouter(); v != nil {
+			v.versioning.removeRoute(v.version, route)
+		}
+	}
+	root.table = table
+	// Роуты удаляются из всех деревьев, включая диспетчеры версий
+	for _, owner := range append([]*Router{root}, root.hosts...) {
+		if owner.tree != nil {
+			owner.tree.removeRoutes(fn)
+		}
+	}
+	for name, route := range root.names {
+		if fn(route) {
+			delete(root.names, name)
+		}
+	}
+	conflicts := root.conflicts[:0]
+	for _, err := range root.conflicts {
+		if e, ok := err.(*RouteConflictError); !ok || !fn(e.route) {
+			conflicts = append(conflicts, err)
+		}
+	}
+	root.conflicts = conflicts
+	return removed
+}
+
+func (n *routeNode) removeRoutes(fn func(route *Router) bool) {
+	for method, routes := range n.routes {
+		list := make([]*Router, 0, len(routes))
+		for _, route := range routes {
+			if !fn(route) {
+				list = append(list, route)
+			}
+		}
+		if len(list) > 0 {
+			n.routes[method] = list
+		} else {
+			delete(n.routes, method)
+		}
+	}
+	for _, c := range n.static {
+		c.removeRoutes(fn)
+	}
+	for _, c := range n.params {
+		c.removeRoutes(fn)
+	}
+	for _, c := range n.catchAll {
+		c.removeRoutes(fn)
+	}
+}
+
+func (v *versionedRouter) removeRoute(version *apiVersion, route *Router) {
+	for _, versions := range v.routes {
+		routes := versions[version]
+		for i, item := range routes {
+			if item == route {
+				versions[version] = append(routes[:i:i], routes[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+// Selection of the routes of the router by the HTTP method (empty - all methods) and the relative path.
+func (r *Router) ownRoutes(httpMethod, relativePath string) func(route *Router) bool {
+	basePath := joinPaths(r.basePath, strings.TrimRight(relativePath, "/"))
+	return func(route *Router) bool {
+		return route.parent == r && route.basePath == basePath && (len(httpMethod) < 1 || route.method == httpMethod)
+	}
+}
+
+// Unregister the routes of the router by the HTTP method (empty - all methods) and the relative path.
+// Safe for concurrent processing of requests, requests being processed are completed by the removed route.
+func (r *Router) Unregister(httpMethod, relativePath string) bool {
+	root := r.rootRouter()
+	root.mutex.Lock()
+	defer root.mutex.Unlock()
+	r.lastRoutes = nil
+	return r.removeRoutes(r.ownRoutes(httpMethod, relativePath))
+}
+
+// Replace the routes of the router by the HTTP method and the relative path with the new route,
+// requests are processed by the old or the new route without the moment when the route is not available.
+// The name, metadata, matchers and body options of the replaced route are kept.
+func (r *Router) Replace(httpMethod, relativePath string, handlers ...HandlerFunc) IRoute {
+	root := r.rootRouter()
+	root.mutex.Lock()
+	defer root.mutex.Unlock()
+	fn := r.ownRoutes(httpMethod, relativePath)
+	var replaced *Router
+	for _, route := range root.table {
+		if fn(route) {
+			replaced = route
+			break
+		}
+	}
+	r.removeRoutes(fn)
+	route := r.registerRoute(httpMethod, relativePath, handlers)
+	if replaced != nil {
+		before := r.routesConflicts([]*Router{route})
+		route.metadata, route.matchers, route.bodyOptions = replaced.metadata, replaced.matchers, replaced.bodyOptions
+		r.recheckRoutes([]*Router{route}, before)
+		if len(replaced.name) > 0 {
+			route.name, root.names[replaced.name] = replaced.name, route
+		}
+	}
+	r.lastRoutes = []*Router{route}
+	return &registeredRoutes{Router: r, routes: r.lastRoutes}
+}
+
+// Remove the group with all its routes and nested groups (including host and versioned groups).
+func (r *Router) RemoveGroup(relativePath string) bool {
+	root := r.rootRouter()
+	root.mutex.Lock()
+	defer root.mutex.Unlock()
+	group, ok := r.groups[relativePath]
+	if !ok {
+		return false
+	}
+	delete(r.groups, relativePath)
+	group.removeRoutes(func(route *Router) bool {
+		return group.isAncestorOf(route)
+	})
+	hosts := root.hosts[:0]
+	for _, h := range root.hosts {
+		if !group.isAncestorOf(h) {
+			hosts = append(hosts, h)
+		}
+	}
+	root.hosts = hosts
+	return true
+}
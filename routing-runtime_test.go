@@ -0,0 +1,126 @@
+package just
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestRuntimeRoutes(t *testing.T) {
+	app := New()
+	handler := func(name string) HandlerFunc {
+		return func(c *Context) IResponse { return &Response{Status: 200, Bytes: []byte(name)} }
+	}
+	status := func(method, path string) (int, string) {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+		return w.Code, w.Body.String()
+	}
+	app.GET("/plugins/{id:integer}", handler("v1")).SetName("plugin").SetMetadata(H{"plugin": true})
+	app.POST("/plugins/{id:integer}", handler("post"))
+	admin := app.Group("/admin")
+	admin.GET("/stats", handler("stats"))
+	admin.Group("/users").GET("/{id}", handler("user"))
+
+	app.Replace("GET", "/plugins/{id:integer}", handler("v2"))
+	if code, body := status("GET", "/plugins/1"); code != 200 || body != "v2" {
+		t.Errorf("expected replaced route, got %d %q", code, body)
+	}
+	if url, err := app.URLFor("plugin", H{"id": 1}); err != nil || url != "/plugins/1" {
+		t.Errorf("expected kept route name, got %q (%v)", url, err)
+	}
+	if routes := app.Routes(); routes[len(routes)-1].Name != "plugin" || routes[len(routes)-1].Metadata["plugin"] != true {
+		t.Errorf("expected kept name and metadata, got %+v", routes[len(routes)-1])
+	}
+
+	if !app.Unregister("POST", "/plugins/{id:integer}") || app.Unregister("POST", "/plugins/{id:integer}") {
+		t.Error("invalid result of unregister")
+	}
+	if code, _ := status("POST", "/plugins/1"); code != 405 {
+		t.Errorf("expected status 405 of removed route, got %d", code)
+	}
+
+	if !app.RemoveGroup("/admin") || app.RemoveGroup("/admin") {
+		t.Error("invalid result of group removal")
+	}
+	for _, path := range []string{"/admin/stats", "/admin/users/1"} {
+		if code, _ := status("GET", path); code != 404 {
+			t.Errorf("%s: expected status 404 of removed group, got %d", path, code)
+		}
+	}
+	if routes := app.Routes(); len(routes) != 1 || routes[0].Path != "/plugins/{id:integer}" {
+		t.Errorf("invalid routes after removal: %+v", routes)
+	}
+}
+
+func TestRuntimeRoutesConcurrency(t *testing.T) {
+	app := New().SetRouteConflictsAsErrors(true)
+	handler := func(c *Context) IResponse { return &Response{Status: 200} }
+	app.GET("/static", handler)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", fmt.Sprintf("/plugins/p%d/run", j%10), nil))
+				w := httptest.NewRecorder()
+				app.ServeHTTP(w, httptest.NewRequest("GET", "/static", nil))
+				if w.Code != 200 {
+					t.Errorf("expected status 200 of static route, got %d", w.Code)
+				}
+				app.Routes()
+			}
+		}()
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for j := 0; j < 200; j++ {
+			group := app.Group(fmt.Sprintf("/plugins/p%d", j%10))
+			group.GET("/run", handler).SetName(fmt.Sprintf("p%d", j%10))
+			group.Replace("GET", "/run", handler)
+			app.RemoveGroup(fmt.Sprintf("/plugins/p%d", j%10))
+		}
+	}()
+	wg.Wait()
+	if routes := app.Routes(); len(routes) != 1 {
+		t.Errorf("expected only static route, got %+v", routes)
+	}
+}
+
+func TestRuntimeRoutesConcurrentWriters(t *testing.T) {
+	app := New()
+	handler := func(c *Context) IResponse { return &Response{Status: 200} }
+	group := app.Group("/plugins")
+
+	// Несколько горутин регистрируют роуты в одной группе
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				name := fmt.Sprintf("p%d-%d", i, j)
+				group.GET("/"+name, handler).SetName(name).SetMetadata(H{"name": name}).
+					Match(MatchAccept("application/json")).SetBodyOptions(BodyOptions{MaxSize: int64(j + 1)})
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	routes := app.Routes()
+	if len(routes) != 8*50 {
+		t.Fatalf("expected %d routes, got %d", 8*50, len(routes))
+	}
+	for _, route := range routes {
+		if route.Path != "/plugins/"+route.Name || route.Metadata["name"] != route.Name || len(route.Matchers) != 1 {
+			t.Errorf("route %s got options of other route: %+v", route.Path, route)
+		}
+		if url, err := app.URLFor(route.Name, nil); err != nil || url != route.Path {
+			t.Errorf("route %s: unexpected url %q (%v)", route.Path, url, err)
+		}
+	}
+}
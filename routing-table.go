@@ -0,0 +1,101 @@
+package just
+
+import "encoding/xml"
+
+// Description of the route parameter.
+type RouteParamDescriptor struct {
+	Name     string `json:"name" xml:"name,attr"`
+	Type     string `json:"type,omitempty" xml:"type,attr,omitempty"`
+	Optional bool   `json:"optional,omitempty" xml:"optional,attr,omitempty"`
+}
+
+// Description of the registered route.
+type RouteDescriptor struct {
+	XMLName    xml.Name               `json:"-" xml:"route"`
+	Name       string                 `json:"name,omitempty" xml:"name,attr,omitempty"`
+	Method     string                 `json:"method" xml:"method,attr"`
+	Host       string                 `json:"host,omitempty" xml:"host,attr,omitempty"`
+	Path       string                 `json:"path" xml:"path,attr"`
+	Version    string                 `json:"version,omitempty" xml:"version,attr,omitempty"`
+	Params     []RouteParamDescriptor `json:"params,omitempty" xml:"params>param,omitempty"`
+	Groups     []string               `json:"groups,omitempty" xml:"groups>group,omitempty"` // Paths of the groups from the outer to the inner.
+	Matchers   []string               `json:"matchers,omitempty" xml:"matchers>matcher,omitempty"`
+	Metadata   H                      `json:"metadata,omitempty" xml:"metadata,omitempty"`
+	Handlers   int                    `json:"handlers" xml:"handlers"`     // Number of handlers of the route.
+	Middleware int                    `json:"middleware" xml:"middleware"` // Number of middleware of the groups.
+}
+
+type routeTable struct {
+	XMLName xml.Name          `json:"-" xml:"routes"`
+	Routes  []RouteDescriptor `json:"routes" xml:"route"`
+}
+
+func describeRoute(route *Router) RouteDescriptor {
+	d := RouteDescriptor{
+		Name:       route.name,
+		Method:     route.method,
+		Path:       route.basePath,
+		Handlers:   len(route.handlers) - route.countMiddleware,
+		Middleware: route.countMiddleware,
+		Metadata:   route.mergedMetadata(),
+	}
+	for _, m := range route.matchers {
+		d.Matchers = append(d.Matchers, m.String())
+	}
+	for _, s := range route.segments {
+		for i, name := range s.names {
+			d.Params = append(d.Params, RouteParamDescriptor{Name: name, Type: s.types[i], Optional: s.optional})
+		}
+	}
+	// Группы от корневого роутера к роуту
+	for g := route.parent; g != nil && g.parent != nil; g = g.parent {
+		if len(g.host) > 0 {
+			if len(d.Host) < 1 {
+				d.Host = g.host
+			}
+			continue
+		}
+		if g.version != nil {
+			if len(d.Version) < 1 {
+				d.Version = g.version.name
+			}
+			continue
+		}
+		d.Groups = append([]string{g.basePath}, d.Groups...)
+	}
+	return d
+}
+
+// Checking that the router is the parent (or the router itself).
+func (r *Router) isAncestorOf(route *Router) bool {
+	for ; route != nil; route = route.parent {
+		if route == r {
+			return true
+		}
+	}
+	return false
+}
+
+// Description of the routes registered by the router and its groups, in order of registration.
+func (r *Router) Routes() []RouteDescriptor {
+	root := r.rootRouter()
+	root.mutex.RLock()
+	defer root.mutex.RUnlock()
+	table := root.table
+	result := make([]RouteDescriptor, 0, len(table))
+	for _, route := range table {
+		if r.isAncestorOf(route) {
+			result = append(result, describeRoute(route))
+		}
+	}
+	return result
+}
+
+// Handler of the table of routes, works only in debug mode (otherwise 404).
+// `app.GET("/_routes", just.RouteTableHandler)`
+func RouteTableHandler(c *Context) IResponse {
+	if !IsDebug() {
+		return noRouteResponse(c)
+	}
+	return c.Serializer().Response(200, &routeTable{Routes: c.app.Routes()})
+}
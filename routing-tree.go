@@ -0,0 +1,576 @@
+package just
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Kind of the route path segment.
+type segmentKind uint8
+
+const (
+	segmentStatic   segmentKind = iota // Plain text segment (/users).
+	segmentParam                       // Segment with parameters (/{id:int}, /{name}.{ext}).
+	segmentCatchAll                    // Segment with path parameter, can capture several segments (/{filepath:path}).
+)
+
+// One segment of the route path (text between slashes).
+type pathSegment struct {
+	kind     segmentKind
+	raw      string           // Source text of the segment.
+	names    []string         // Names of the parameters.
+	types    []string         // Types of the parameters (int, uuid, ...).
+	parts    []string         // Text around the parameters (len(parts) == len(names) + 1).
+	checks   []*regexp.Regexp // Regular expressions to check the values of the parameters (nil - parameter without type).
+	groups   []int            // Indexes of the capture groups of the parameters in rx.
+	rx       *regexp.Regexp   // Regular expression of the segment (nil - static segment or one parameter without type).
+	optional bool             // The segment can be absent in the path (/{month:int?}).
+}
+
+// Node of the routing tree.
+type routeNode struct {
+	segment  pathSegment
+	static   map[string]*routeNode // Children with plain text segments (map[segment]*routeNode).
+	params   []*routeNode          // Children with parameters in order of priority (typed first), then in order of registration.
+	catchAll []*routeNode          // Children with path parameters in order of registration.
+	routes   map[string][]*Router  // Routes of the node (map[httpMethod][]*Router).
+}
+
+// Returns the regular expression of the route parameter by its type (registered types, regexp(...) and enum(...)).
+func paramTypePattern(t string) (pattern string, isPath bool) {
+	if len(t) < 1 {
+		return patternParamString, false
+	}
+	if pt, ok := lookupParamType(t); ok {
+		return pt.pattern, pt.isPath
+	}
+	if begin, end := strings.IndexByte(t, '('), strings.LastIndexByte(t, ')'); begin > 0 && end > begin {
+		if value := strings.TrimSpace(t[begin+1 : end]); len(value) > 0 {
+			switch strings.TrimSpace(t[:begin]) {
+			case "rgx", "regexp":
+				return "(?:" + value + ")", false
+			case "e", "enum":
+				return "(?:" + strings.Join(strings.FieldsFunc(value, func(c rune) bool {
+					return !unicode.IsLetter(c) && !unicode.IsNumber(c)
+				}), "|") + ")", false
+			}
+		}
+	}
+	return patternParamString, false
+}
+
+// Returns the position of the closing brace of the parameter, nested braces are taken into account.
+func closingBraceIndex(s string, begin int) int {
+	depth := 0
+	for i := begin; i < len(s); i++ {
+		switch s[i] {
+		case '{':
+			depth++
+		case '}':
+			if depth--; depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// Splits the route path (or host) into segments, separators inside the parameters are ignored.
+func splitRoutePattern(p string, separator byte) []string {
+	segments, start := make([]string, 0, strings.Count(p, string(separator))+1), 0
+	for i := 0; i < len(p); i++ {
+		switch p[i] {
+		case '{':
+			if end := closingBraceIndex(p, i); end > 0 {
+				i = end
+			}
+		case separator:
+			if i > start {
+				segments = append(segments, p[start:i])
+			}
+			start = i + 1
+		}
+	}
+	if start < len(p) {
+		segments = append(segments, p[start:])
+	}
+	return segments
+}
+
+func parsePathSegment(raw string) pathSegment {
+	s := pathSegment{kind: segmentStatic, raw: raw}
+	if strings.IndexByte(raw, '{') < 0 {
+		return s
+	}
+	var pattern bytes.Buffer
+	pattern.WriteByte('^')
+	pos, optional := 0, false
+	for pos < len(raw) {
+		begin := strings.IndexByte(raw[pos:], '{')
+		if begin < 0 {
+			break
+		}
+		begin += pos
+		end := closingBraceIndex(raw, begin)
+		if end < 0 {
+			break
+		}
+		s.parts = append(s.parts, raw[pos:begin])
+		pattern.WriteString(regexp.QuoteMeta(raw[pos:begin]))
+		// Анализ параметра
+		name, t := strings.TrimSpace(raw[begin+1:end]), ""
+		if i := strings.IndexByte(name, ':'); i > 0 {
+			name, t = strings.TrimSpace(name[:i]), strings.TrimSpace(name[i+1:])
+		}
+		// Остаток пути {rest...} и необязательные параметры {name?}, {name:int?}
+		if strings.HasSuffix(name, "...") && len(t) < 1 {
+			name, t = strings.TrimSpace(strings.TrimSuffix(name, "...")), "path"
+		}
+		if strings.HasSuffix(t, "?") {
+			t, optional = strings.TrimSpace(strings.TrimSuffix(t, "?")), true
+		} else if strings.HasSuffix(name, "?") {
+			name, optional = strings.TrimSpace(strings.TrimSuffix(name, "?")), true
+		}
+		rx, isPath := paramTypePattern(t)
+		var check *regexp.Regexp
+		if len(t) > 0 {
+			check = regexp.MustCompile("^" + rx + "$")
+		}
+		if isPath {
+			s.kind = segmentCatchAll
+		} else if s.kind == segmentStatic {
+			s.kind = segmentParam
+		}
+		pattern.WriteString("(?P<p" + strconv.Itoa(len(s.names)) + ">" + rx + ")")
+		s.names, s.types, s.checks = append(s.names, name), append(s.types, t), append(s.checks, check)
+		pos = end + 1
+	}
+	if s.kind == segmentStatic {
+		return s
+	}
+	s.parts = append(s.parts, raw[pos:])
+	pattern.WriteString(regexp.QuoteMeta(raw[pos:]))
+	if optional {
+		if s.kind != segmentParam || len(s.names) != 1 || len(s.parts[0]) > 0 || len(s.parts[1]) > 0 {
+			panic(fmt.Errorf("the optional route param [%s] must be the whole segment", raw))
+		}
+		s.optional = true
+	}
+	// Параметр без типа на весь сегмент проверяется без регулярного выражения
+	if s.kind == segmentParam && len(s.names) == 1 && len(s.types[0]) == 0 && raw[0] == '{' && raw[len(raw)-1] == '}' {
+		return s
+	}
+	pattern.WriteByte('$')
+	s.rx = regexp.MustCompile(pattern.String())
+	s.groups = make([]int, len(s.names))
+	for i, n := range s.rx.SubexpNames() {
+		if len(n) > 1 && n[0] == 'p' {
+			if index, err := strconv.Atoi(n[1:]); err == nil && index < len(s.groups) {
+				s.groups[index] = i
+			}
+		}
+	}
+	return s
+}
+
+// Parse the route path into segments and the list of parameter names.
+func parseRoutePath(p string) (segments []pathSegment, paramNames []string) {
+	return parseRoutePattern(p, '/')
+}
+
+// Parse the host pattern into segments (labels of the domain) and the list of parameter names.
+func parseHostPattern(host string) (segments []pathSegment, paramNames []string) {
+	return parseRoutePattern(strings.ToLower(host), '.')
+}
+
+func parseRoutePattern(p string, separator byte) (segments []pathSegment, paramNames []string) {
+	for _, raw := range splitRoutePattern(p, separator) {
+		s := parsePathSegment(raw)
+		if len(s.names) > 0 {
+			paramNames = append(paramNames, s.names...)
+		}
+		segments = append(segments, s)
+	}
+	return
+}
+
+func segmentsParamType(segments []pathSegment, name string) (string, bool) {
+	for i := range segments {
+		for j, n := range segments[i].names {
+			if n == name {
+				return segments[i].types[j], true
+			}
+		}
+	}
+	return "", false
+}
+
+func hasPatternParams(segments []pathSegment) bool {
+	for i := range segments {
+		if segments[i].kind != segmentStatic {
+			return true
+		}
+	}
+	return false
+}
+
+// Checking the value of the segment, the values of the parameters are added to the list of pairs (name, value).
+func (s *pathSegment) match(value string, values []string) ([]string, bool) {
+	if s.kind == segmentStatic {
+		return values, s.raw == value
+	}
+	if s.rx == nil {
+		if len(value) < 1 {
+			return values, false
+		}
+		return append(values, s.names[0], value), true
+	}
+	indexes := s.rx.FindStringSubmatchIndex(value)
+	if indexes == nil {
+		return values, false
+	}
+	for i, name := range s.names {
+		if g := s.groups[i] * 2; indexes[g] >= 0 {
+			values = append(values, name, value[indexes[g]:indexes[g+1]])
+		} else {
+			values = append(values, name, "")
+		}
+	}
+	return values, true
+}
+
+// Building the segment by the values of the parameters.
+func (s *pathSegment) build(params H) (string, error) {
+	if s.kind == segmentStatic {
+		return s.raw, nil
+	}
+	var buffer bytes.Buffer
+	for i, name := range s.names {
+		buffer.WriteString(s.parts[i])
+		v, ok := params[name]
+		if !ok || v == nil {
+			return "", &RouteParamError{Param: name, Message: "missing value"}
+		}
+		value := fmt.Sprint(v)
+		if s.checks[i] != nil {
+			if !s.checks[i].MatchString(value) {
+				return "", &RouteParamError{Param: name, Message: "is not " + s.types[i]}
+			}
+		} else if len(value) < 1 {
+			return "", &RouteParamError{Param: name, Message: "empty value"}
+		}
+		if s.kind == segmentCatchAll {
+			parts := strings.Split(value, "/")
+			for j := range parts {
+				parts[j] = url.PathEscape(parts[j])
+			}
+			value = strings.Join(parts, "/")
+		} else {
+			value = url.PathEscape(value)
+		}
+		buffer.WriteString(value)
+	}
+	buffer.WriteString(s.parts[len(s.names)])
+	return buffer.String(), nil
+}
+
+// Building the path by the segments and the values of the parameters.
+func buildRoutePath(segments []pathSegment, params H) (string, error) {
+	var buffer bytes.Buffer
+	for i := range segments {
+		if segments[i].optional {
+			if v, ok := params[segments[i].names[0]]; !ok || v == nil {
+				continue
+			}
+		}
+		value, err := segments[i].build(params)
+		if err != nil {
+			return "", err
+		}
+		if len(value) > 0 {
+			buffer.WriteByte('/')
+			buffer.WriteString(value)
+		}
+	}
+	if buffer.Len() < 1 {
+		return "/", nil
+	}
+	return buffer.String(), nil
+}
+
+// The last segment of the route path is catch-all ({x:path}, {rest...}).
+func (r *Router) endsWithCatchAll() bool {
+	return len(r.segments) > 0 && r.segments[len(r.segments)-1].kind == segmentCatchAll
+}
+
+// Leads the path of the request to the form of the routing tree (without the final slash, the root is an empty string).
+func normalizeRequestPath(p string) string {
+	if len(p) > 0 && p[0] != '/' {
+		p = "/" + p
+	}
+	if l := len(p); l > 0 && p[l-1] == '/' {
+		p = p[:l-1]
+	}
+	return p
+}
+
+// Leads the host of the request to the form of the path (without port, labels are separated by slashes).
+func normalizeRequestHost(host string) string {
+	if i := strings.LastIndexByte(host, ':'); i >= 0 && strings.IndexByte(host[i:], ']') < 0 {
+		host = host[:i]
+	}
+	return "/" + strings.Replace(strings.ToLower(strings.TrimSuffix(host, ".")), ".", "/", -1)
+}
+
+// Returns the next segment of the path and the rest of the path (the path starts with a slash).
+func nextPathSegment(p string) (segment, rest string) {
+	if i := strings.IndexByte(p[1:], '/'); i >= 0 {
+		return p[1 : i+1], p[i+1:]
+	}
+	return p[1:], ""
+}
+
+// Goes through all variants of capturing the path by the path parameter, starting with the shortest.
+func eachPathCapture(p string, fn func(value, rest string) bool) bool {
+	if fn("", p) {
+		return true
+	}
+	for i := 0; i < len(p); {
+		if next := strings.IndexByte(p[i+1:], '/'); next >= 0 {
+			i += next + 1
+		} else {
+			i = len(p)
+		}
+		if fn(p[1:i], p[i:]) {
+			return true
+		}
+	}
+	return false
+}
+
+// Checking the path for compliance with the segments (exactly - the whole path, otherwise by prefix).
+func matchPathSegments(segments []pathSegment, p string, values []string, exactly bool) ([]string, bool) {
+	if len(segments) < 1 {
+		return values, !exactly || len(p) < 1
+	}
+	s := &segments[0]
+	if s.optional {
+		// Необязательный сегмент: сначала со значением, затем без него
+		if len(p) > 0 {
+			segment, rest := nextPathSegment(p)
+			if v, ok := s.match(segment, values); ok {
+				if result, ok := matchPathSegments(segments[1:], rest, v, exactly); ok {
+					return result, true
+				}
+			}
+		}
+		return matchPathSegments(segments[1:], p, values, exactly)
+	}
+	if s.kind == segmentCatchAll {
+		var result []string
+		ok := eachPathCapture(p, func(value, rest string) bool {
+			if v, ok := s.match(value, values); ok {
+				result, ok = matchPathSegments(segments[1:], rest, v, exactly)
+				return ok
+			}
+			return false
+		})
+		return result, ok
+	}
+	if len(p) < 1 {
+		return values, false
+	}
+	segment, rest := nextPathSegment(p)
+	if v, ok := s.match(segment, values); ok {
+		return matchPathSegments(segments[1:], rest, v, exactly)
+	}
+	return values, false
+}
+
+// The parameter segment matches any value of the segment (/{name}, /{name:string}).
+func (s *pathSegment) matchesAny() bool {
+	if s.kind != segmentParam || len(s.names) != 1 || len(s.parts[0]) > 0 || len(s.parts[1]) > 0 {
+		return false
+	}
+	pattern, _ := paramTypePattern(s.types[0])
+	return pattern == patternParamString
+}
+
+// Priority of the segment in the routing tree (less - checked earlier): plain text, typed parameters,
+// parameters without type, path parameters. Segments with the same priority are checked in order of registration.
+func segmentPriority(s *pathSegment) int {
+	switch {
+	case s.kind == segmentStatic:
+		return 0
+	case s.kind == segmentCatchAll:
+		return 3
+	case s.matchesAny():
+		return 2
+	}
+	return 1
+}
+
+// Returns the child node for the segment, creating it if necessary.
+func (n *routeNode) child(s pathSegment) *routeNode {
+	switch s.kind {
+	case segmentStatic:
+		if n.static == nil {
+			n.static = make(map[string]*routeNode)
+		}
+		if c, ok := n.static[s.raw]; ok {
+			return c
+		}
+		c := &routeNode{segment: s}
+		n.static[s.raw] = c
+		return c
+	case segmentParam:
+		for _, c := range n.params {
+			if c.segment.raw == s.raw {
+				return c
+			}
+		}
+		// Параметры с типом проверяются раньше параметров без типа, независимо от порядка регистрации
+		c, i := &routeNode{segment: s}, len(n.params)
+		for i > 0 && segmentPriority(&n.params[i-1].segment) > segmentPriority(&s) {
+			i--
+		}
+		n.params = append(n.params, nil)
+		copy(n.params[i+1:], n.params[i:])
+		n.params[i] = c
+		return c
+	}
+	for _, c := range n.catchAll {
+		if c.segment.raw == s.raw {
+			return c
+		}
+	}
+	c := &routeNode{segment: s}
+	n.catchAll = append(n.catchAll, c)
+	return c
+}
+
+// Variants of the segments with and without the optional segments, starting with the full path.
+func segmentVariants(segments []pathSegment) [][]pathSegment {
+	variants := [][]pathSegment{nil}
+	for _, s := range segments {
+		next := make([][]pathSegment, 0, len(variants)*2)
+		for _, v := range variants {
+			next = append(next, append(v[:len(v):len(v)], s))
+			if s.optional {
+				next = append(next, v)
+			}
+		}
+		variants = next
+	}
+	return variants
+}
+
+// Adding a node by the segments of the route path.
+func (n *routeNode) insert(segments []pathSegment) *routeNode {
+	for _, s := range segments {
+		n = n.child(s)
+	}
+	return n
+}
+
+func (n *routeNode) hasRoute(httpMethod string, req *http.Request) bool {
+	if len(httpMethod) < 1 {
+		return len(n.routes) > 0
+	}
+	return n.selectRoute(httpMethod, req) != nil
+}
+
+// Search for the node with the route of the HTTP method (empty method - any route), the route must match the request (nil - without checking).
+// Plain text segments are checked first, then parameters (typed first) and at the end the path parameters,
+// if the subtree has no route for the path the next sibling node is checked.
+func (n *routeNode) find(httpMethod, p string, values []string, req *http.Request) (*routeNode, []string) {
+	if len(p) < 1 {
+		if n.hasRoute(httpMethod, req) {
+			return n, values
+		}
+	} else {
+		segment, rest := nextPathSegment(p)
+		if c, ok := n.static[segment]; ok {
+			if found, v := c.find(httpMethod, rest, values, req); found != nil {
+				return found, v
+			}
+		}
+		for _, c := range n.params {
+			if v, ok := c.segment.match(segment, values); ok {
+				if found, v := c.find(httpMethod, rest, v, req); found != nil {
+					return found, v
+				}
+			}
+		}
+	}
+	for _, c := range n.catchAll {
+		var (
+			found  *routeNode
+			result []string
+		)
+		if eachPathCapture(p, func(value, rest string) bool {
+			if v, ok := c.segment.match(value, values); ok {
+				found, result = c.find(httpMethod, rest, v, req)
+			}
+			return found != nil
+		}) {
+			return found, result
+		}
+	}
+	return nil, values
+}
+
+// Search for the route path ignoring the case of the plain text segments, returns the path in the registered form.
+func (n *routeNode) findFold(httpMethod, p, fixed string, values []string, req *http.Request) (string, bool) {
+	if len(p) < 1 {
+		if n.hasRoute(httpMethod, req) {
+			return fixed, true
+		}
+	} else {
+		segment, rest := nextPathSegment(p)
+		if c, ok := n.static[segment]; ok {
+			if f, ok := c.findFold(httpMethod, rest, fixed+"/"+segment, values, req); ok {
+				return f, true
+			}
+		}
+		for key, c := range n.static {
+			if key != segment && strings.EqualFold(key, segment) {
+				if f, ok := c.findFold(httpMethod, rest, fixed+"/"+key, values, req); ok {
+					return f, true
+				}
+			}
+		}
+		for _, c := range n.params {
+			if v, ok := c.segment.match(segment, values); ok {
+				if f, ok := c.findFold(httpMethod, rest, fixed+"/"+segment, v, req); ok {
+					return f, true
+				}
+			}
+		}
+	}
+	for _, c := range n.catchAll {
+		var (
+			result string
+			found  bool
+		)
+		if eachPathCapture(p, func(value, rest string) bool {
+			if v, ok := c.segment.match(value, values); ok {
+				if len(value) > 0 {
+					result, found = c.findFold(httpMethod, rest, fixed+"/"+value, v, req)
+				} else {
+					result, found = c.findFold(httpMethod, rest, fixed, v, req)
+				}
+			}
+			return found
+		}) {
+			return result, true
+		}
+	}
+	return "", false
+}
@@ -1,17 +1,18 @@
 package just
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"path"
 	"regexp"
+	"sort"
 	"strings"
-	"unicode"
+	"sync"
 )
 
-var (
-	rxPathFindParams = regexp.MustCompile(`\{([^/]*?)\}`)
-)
+// Name of the route parameter with the path of the request to the mounted handler.
+const mountParamName = "_mount"
 
 const (
 	patternParamPath    = "(.*?)"
@@ -25,12 +26,31 @@ const (
 	patternHex          = "((0[xX])?[0-9a-fA-F]+)"
 )
 
+// Errors
+var (
+	ErrRouteNameNotFound = errors.New("route with this name not found")
+)
+
+// Error of the route parameter when building the URL.
+type RouteParamError struct {
+	Param   string
+	Message string
+}
+
+// Route parameter error text.
+func (e *RouteParamError) Error() string {
+	return "Invalid route param \"" + e.Param + "\" - " + e.Message
+}
+
 // Method of processing a request or middleware.
 type HandlerFunc func(*Context) IResponse
 
 // Interface information on route.
 type IRouteInfo interface {
+	Name() string
 	BasePath() string
+	Metadata() H
+	MetadataValue(key string) (interface{}, bool)
 	CountHandlers() int
 	HandlerByIndex(index int) (HandlerFunc, bool)
 }
@@ -42,6 +62,16 @@ type IRoute interface {
 	// Use middleware.
 	Use(...HandlerFunc) IRoute
 
+	// Set the name of the last registered route (the routes of the registration, when called on its result).
+	SetName(string) IRoute
+
+	// Add matchers of the request to the last registered routes (the routes of the registration, when called on its result).
+	Match(...IRouteMatcher) IRoute
+
+	// Add metadata to the last registered routes (the group without routes - to all its routes).
+	SetMetadata(H) IRoute
+	SetBodyOptions(BodyOptions) IRoute
+
 	// Processing of requests to the application server.
 	Handle(string, string, ...HandlerFunc) IRoute
 	ANY(string, ...HandlerFunc) IRoute
@@ -55,8 +85,12 @@ type IRoute interface {
 
 	StaticFile(string, string) IRoute
 
-	Static(string, string) IRoute
-	StaticFS(string, http.FileSystem) IRoute
+	Static(string, string, ...StaticOptions) IRoute
+	StaticFS(string, http.FileSystem, ...StaticOptions) IRoute
+
+	// Processing of requests by the prefix with the standard handler or other application.
+	Mount(string, http.Handler) IRoute
+	MountApp(string, IApplication) IRoute
 
 	CheckPath(string) (map[string]string, bool)
 }
@@ -65,17 +99,55 @@ type IRoute interface {
 type IRouter interface {
 	IRoute
 	Group(string, ...HandlerFunc) IRouter
+
+	// Description of the routes registered by the router and its groups.
+	Routes() []RouteDescriptor
+
+	// Create versioned group router (API versions selected by URL prefix, Accept vendor type or header).
+	Versioned(string, VersioningOptions, ...HandlerFunc) IVersionedRouter
+
+	// Create group router by the host of the request (api.example.com, {tenant}.example.com).
+	Host(string, ...HandlerFunc) IRouter
+
+	// Building the URL of the named route.
+	URLFor(string, H) (string, error)
+
+	// Changing the routes at runtime, safe for concurrent processing of requests.
+	Replace(string, string, ...HandlerFunc) IRoute
+	Unregister(string, string) bool
+	RemoveGroup(string) bool
 }
 
 // Base Router struct.
 type Router struct {
-	basePath        string              // The way that process route.
-	rxPath          *regexp.Regexp      // The regular expression used to validate the path.
-	handlers        []HandlerFunc       // The list of processors, including middleware available for this route.
-	routeParamNames []string            // A list of detected parameters in their path.
-	parent          *Router             // A pointer to the parent router.
-	groups          map[string]*Router  // Routers (map[relativePath]*Router).
-	routes          map[string][]IRoute // Routes with grouping by method (map[httpMethod][]IRoute).
+	name             string              // Name of the route to build URL.
+	method           string              // HTTP method of the route (empty for group).
+	basePath         string              // The way that process route.
+	segments         []pathSegment       // Segments of the path used to validate the path.
+	handlers         []HandlerFunc       // The list of processors, including middleware available for this route.
+	countMiddleware  int                 // Number of middleware of the groups at the beginning of the handlers.
+	routeParamNames  []string            // A list of detected parameters in their path.
+	exactly          bool                // The path is checked completely (route), otherwise by prefix (group).
+	parent           *Router             // A pointer to the parent router.
+	groups           map[string]*Router  // Routers (map[relativePath]*Router).
+	routes           map[string][]IRoute // Routes with grouping by method (map[httpMethod][]IRoute).
+	lastRoutes       []*Router           // The last registered routes.
+	matchers         []IRouteMatcher     // Matchers of the request for the route.
+	metadata         H                   // Metadata of the route or the group (description, scopes, ...).
+	bodyOptions      *BodyOptions        // Options of the request body of the route or the group (nil - options of the parent).
+	host             string              // Host pattern of the group.
+	hostSegments     []pathSegment       // Segments (labels) of the host pattern.
+	version          *apiVersion         // Version of the API of the group.
+	versioning       *versionedRouter    // Versioned group that owns the version group.
+	dispatcher       bool                // Route of the dispatcher of the versioned group (the body is prepared for the selected route).
+	tree             *routeNode          // Routing tree (only in the root router and host groups).
+	methods          []string            // Registered HTTP methods (only in the root router and host groups).
+	hosts            []*Router           // Host groups, the groups without parameters first (only in the root router).
+	names            map[string]*Router  // Named routes (only in the root router).
+	table            []*Router           // Routes in order of registration (only in the root router).
+	conflicts        []error             // Collected route conflicts (only in the root router).
+	collectConflicts bool                // Collect route conflicts instead of panic (only in the root router).
+	mutex            sync.RWMutex        // Lock of the routing tables for registration at runtime (only in the root router).
 }
 
 func connectHandlersByRouter(r *Router, handlers []HandlerFunc) []HandlerFunc {
@@ -85,93 +157,52 @@ func connectHandlersByRouter(r *Router, handlers []HandlerFunc) []HandlerFunc {
 	return handlers
 }
 
-func regularityBasePath(basePath string, exactly bool, supportEndSlash bool) (rxPath *regexp.Regexp, paramNames []string) {
-	if strings.IndexByte(basePath, '{') < 0 {
-		return
-	}
-	params := rxPathFindParams.FindAllStringSubmatch(basePath, -1)
-	if len(params) < 1 {
-		return
-	}
-	paramNames = make([]string, len(params))
-	regExpPattern := basePath
-	for i, param := range params {
-		if len(param) == 0 {
-			continue
-		}
-		if len(param) > 1 {
-			// Анализ параметра
-			if pos := strings.IndexByte(param[1], ':'); pos > 0 {
-				paramNames[i] = strings.TrimSpace(param[1][0:pos])
-				if req := strings.TrimSpace(param[1][pos+1:]); len(req) > 1 {
-					// Анализ рекомендаций параметра
-					findPattern := true
-					switch req {
-					case "p", "path":
-						regExpPattern = strings.Replace(regExpPattern, param[0], patternParamPath, 1)
-					case "hex":
-						regExpPattern = strings.Replace(regExpPattern, param[0], patternHex, 1)
-					case "rid":
-						regExpPattern = strings.Replace(regExpPattern, param[0], patternParamRID, 1)
-					case "uuid":
-						regExpPattern = strings.Replace(regExpPattern, param[0], patternParamUUID, 1)
-					case "i", "int", "integer":
-						regExpPattern = strings.Replace(regExpPattern, param[0], patternParamInteger, 1)
-					case "f", "number", "float":
-						regExpPattern = strings.Replace(regExpPattern, param[0], patternParamFloat, 1)
-					case "b", "bool", "boolean":
-						regExpPattern = strings.Replace(regExpPattern, param[0], patternParamBoolean, 1)
-					case "f.e", "file.ext":
-						regExpPattern = strings.Replace(regExpPattern, param[0], patternParamFileExt, 1)
-					default:
-						{
-							findPattern = false
-							if begin, end := strings.IndexByte(req, '('), strings.LastIndexByte(req, ')'); begin > 0 && end > begin {
-								if t := strings.TrimSpace(req[:begin]); len(t) > 0 {
-									if findPattern = t == "regexp" || t == "enum"; findPattern && len(strings.TrimSpace(req[begin+1:end])) > 0 {
-										switch t {
-										case "rgx", "regexp":
-											regExpPattern = strings.Replace(regExpPattern, param[0], strings.TrimSpace(req[begin:]), 1)
-										case "e", "enum":
-											regExpPattern = strings.Replace(regExpPattern, param[0], "("+strings.Join(strings.FieldsFunc(req[begin+1:end], func(c rune) bool {
-												return !unicode.IsLetter(c) && !unicode.IsNumber(c)
-											}), "|")+")", 1)
-										}
-									}
-								}
-							}
-						}
-					}
-					if findPattern {
-						continue
-					}
-				}
-			} else {
-				paramNames[i] = strings.TrimSpace(param[1])
-			}
-		} else {
-			paramNames[i] = strings.TrimSpace(param[0])
-		}
-		regExpPattern = strings.Replace(regExpPattern, param[0], patternParamString, 1)
-	}
-	var (
-		err error
-		end string
-	)
-	if supportEndSlash {
-		end = "(\\/)?"
-	}
-	if exactly {
-		end += "$"
-	}
-	rxPath, err = regexp.Compile("^" + regExpPattern + end)
-	if err != nil {
-		panic(err)
+func (r *Router) handle(httpMethod string, relativePath string, handlers []HandlerFunc) IRoute {
+	return r.handleMethods([]string{httpMethod}, relativePath, handlers)
+}
+
+// Registration of the route by the HTTP methods, safe for concurrent processing of requests.
+func (r *Router) handleMethods(httpMethods []string, relativePath string, handlers []HandlerFunc) IRoute {
+	root := r.rootRouter()
+	root.mutex.Lock()
+	defer root.mutex.Unlock()
+	lastRoutes := make([]*Router, 0, len(httpMethods))
+	for _, method := range httpMethods {
+		lastRoutes = append(lastRoutes, r.registerRoute(method, relativePath, handlers))
 	}
-	return
+	r.lastRoutes = lastRoutes
+	return &registeredRoutes{Router: r, routes: lastRoutes}
 }
 
-func (r *Router) handle(httpMethod string, relativePath string, handlers []HandlerFunc) IRoute {
+// Routes registered by one call (GET, POST, Replace, ...), returned by the registration.
+// SetName, Match, SetMetadata and SetBodyOptions act on these routes, not on the last routes of the group,
+// so concurrent registration on the same group does not change the routes of other calls.
+type registeredRoutes struct {
+	*Router
+	routes []*Router
+}
+
+func (h *registeredRoutes) SetName(name string) IRoute {
+	h.Router.setName(h.routes, name)
+	return h
+}
+
+func (h *registeredRoutes) Match(matchers ...IRouteMatcher) IRoute {
+	h.Router.match(h.routes, matchers)
+	return h
+}
+
+func (h *registeredRoutes) SetMetadata(metadata H) IRoute {
+	h.Router.setMetadata(h.routes, metadata)
+	return h
+}
+
+func (h *registeredRoutes) SetBodyOptions(options BodyOptions) IRoute {
+	h.Router.setBodyOptions(h.routes, options)
+	return h
+}
+
+func (r *Router) registerRoute(httpMethod string, relativePath string, handlers []HandlerFunc) *Router {
 	if r.routes == nil {
 		r.routes = make(map[string][]IRoute)
 	}
@@ -179,28 +210,177 @@ func (r *Router) handle(httpMethod string, relativePath string, handlers []Handl
 		r.routes[httpMethod] = make([]IRoute, 0)
 	}
 	basePath := joinPaths(r.basePath, strings.TrimRight(relativePath, "/"))
-	rxPath, routeParamNames := regularityBasePath(basePath, true, true)
+	segments, routeParamNames := parseRoutePath(basePath)
 	if IsDebug() {
-		if rxPath != nil {
-			fmt.Println("[DEBUG] Registration", httpMethod, "route regexp:", rxPath.String(), routeParamNames)
+		if len(routeParamNames) > 0 {
+			fmt.Println("[DEBUG] Registration", httpMethod, "route:", basePath, routeParamNames)
 		} else {
 			fmt.Println("[DEBUG] Registration", httpMethod, "plain route:", basePath)
 		}
 	}
-	r.routes[httpMethod] = append(r.routes[httpMethod], &Router{
+	route := &Router{
+		method:          httpMethod,
 		basePath:        basePath,
-		rxPath:          rxPath,
+		segments:        segments,
 		handlers:        connectHandlersByRouter(r, handlers),
+		countMiddleware: len(r.handlers),
 		routeParamNames: routeParamNames,
+		exactly:         true,
 		parent:          r,
 		groups:          nil,
 		routes:          nil,
-	})
+	}
+	r.routes[httpMethod] = append(r.routes[httpMethod], route)
+	if v := r.versionRouter(); v != nil {
+		// Роуты версий API выбираются диспетчером группы версий
+		v.versioning.addRoute(v.version, route)
+	} else {
+		r.treeRouter().insertRoute(route)
+	}
+	root := r.rootRouter()
+	root.table = append(root.table, route)
+	return route
+}
+
+// Adding the route to the routing tree of the router (root router or host group).
+func (r *Router) insertRoute(route *Router) {
+	if r.tree == nil {
+		r.tree = &routeNode{}
+	}
+	// Роут с необязательными сегментами добавляется во все узлы вариантов пути
+	for _, segments := range segmentVariants(route.segments) {
+		n := r.tree.insert(segments)
+		if n.routes == nil {
+			n.routes = make(map[string][]*Router)
+		}
+		// При повторной регистрации, как и прежде, используется первый подходящий роут
+		n.routes[route.method] = append(n.routes[route.method], route)
+	}
+	if !hasString(r.methods, route.method) {
+		r.methods = append(r.methods, route.method)
+		sort.Strings(r.methods)
+	}
+	r.checkRoute(route)
+}
+
+func (r *Router) rootRouter() *Router {
+	for r.parent != nil {
+		r = r.parent
+	}
 	return r
 }
 
+// Router that owns the routing tree (root router or host group).
+func (r *Router) treeRouter() *Router {
+	for r.parent != nil && r.hostSegments == nil {
+		r = r.parent
+	}
+	return r
+}
+
+func pathParamsFromValues(values []string) map[string]string {
+	if len(values) < 2 {
+		return nil
+	}
+	params := make(map[string]string, len(values)/2)
+	for i := 0; i+1 < len(values); i += 2 {
+		params[values[i]] = values[i+1]
+	}
+	return params
+}
+
+// Goes through the routing trees suitable for the host of the request: host groups, then the default tree.
+// The values of the host parameters are passed to the function.
+func (r *Router) eachTree(host string, fn func(owner *Router, values []string) bool) bool {
+	if len(r.hosts) > 0 && len(host) > 0 {
+		host = normalizeRequestHost(host)
+		for _, h := range r.hosts {
+			if h.tree == nil {
+				continue
+			}
+			if values, ok := matchPathSegments(h.hostSegments, host, nil, true); ok && fn(h, values) {
+				return true
+			}
+		}
+	}
+	return r.tree != nil && fn(r, nil)
+}
+
+// List of methods by which the path is registered.
+func (r *Router) allowedMethods(host, path string) []string {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	var methods []string
+	path = normalizeRequestPath(path)
+	r.eachTree(host, func(owner *Router, values []string) bool {
+		for _, method := range owner.methods {
+			if !hasString(methods, method) {
+				if n, _ := owner.tree.find(method, path, values, nil); n != nil {
+					methods = append(methods, method)
+				}
+			}
+		}
+		return false
+	})
+	sort.Strings(methods)
+	return methods
+}
+
+// Search for a route in the routing trees by HTTP method, host and path of the request, the route must match the request.
+func (r *Router) findRoute(req *http.Request) (route *Router, params map[string]string) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	path := normalizeRequestPath(req.URL.Path)
+	r.eachTree(req.Host, func(owner *Router, values []string) bool {
+		if n, values := owner.tree.find(req.Method, path, values, req); n != nil {
+			route, params = n.selectRoute(req.Method, req), pathParamsFromValues(values)
+			return true
+		}
+		return false
+	})
+	return
+}
+
+// Canonical path of the registered route for the request path with duplicate slashes, dot segments
+// (and in other case of letters if caseInsensitive), false - no route.
+func (r *Router) fixedPath(req *http.Request, caseInsensitive bool) (fixed string, ok bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	p := normalizeRequestPath(path.Clean("/" + req.URL.Path))
+	r.eachTree(req.Host, func(owner *Router, values []string) bool {
+		if caseInsensitive {
+			fixed, ok = owner.tree.findFold(req.Method, p, "", values, req)
+		} else if n, _ := owner.tree.find(req.Method, p, values, req); n != nil {
+			fixed, ok = p, true
+		}
+		return ok
+	})
+	if ok && len(fixed) < 1 {
+		fixed = "/"
+	}
+	return
+}
+
+// Status when the path and the method of the request are registered, but the request does not match the matchers of the routes (0 - no routes).
+func (r *Router) mismatchStatus(req *http.Request) (status int) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	path := normalizeRequestPath(req.URL.Path)
+	r.eachTree(req.Host, func(owner *Router, values []string) bool {
+		if n, _ := owner.tree.find(req.Method, path, values, nil); n != nil {
+			status = n.mismatchStatus(req.Method, req)
+			return true
+		}
+		return false
+	})
+	return
+}
+
 // Use middleware.
 func (r *Router) Use(middleware ...HandlerFunc) IRoute {
+	root := r.rootRouter()
+	root.mutex.Lock()
+	defer root.mutex.Unlock()
 	if r.handlers == nil {
 		r.handlers = make([]HandlerFunc, 0)
 	}
@@ -208,24 +388,69 @@ func (r *Router) Use(middleware ...HandlerFunc) IRoute {
 	return r
 }
 
+// Set the name of the last registered route (the group without routes is named itself).
+// Routes with the same path registered by other methods get the same name.
+// With concurrent registration on the same group use the result of the registration (`g.GET(...).SetName(...)`).
+func (r *Router) SetName(name string) IRoute {
+	r.setName(nil, name)
+	return r
+}
+
+// Names the routes (nil - the last registered routes of the router).
+func (r *Router) setName(targets []*Router, name string) {
+	root := r.rootRouter()
+	root.mutex.Lock()
+	defer root.mutex.Unlock()
+	if root.names == nil {
+		root.names = make(map[string]*Router)
+	}
+	if _, ok := root.names[name]; ok {
+		fmt.Println("[WARNING] Re-registration of", name, "route name")
+	}
+	if targets == nil {
+		targets = r.lastRoutes
+	}
+	if len(targets) < 1 {
+		r.name, root.names[name] = name, r
+		return
+	}
+	last := targets[0]
+	for _, routes := range r.routes {
+		for _, route := range routes {
+			if router, ok := route.(*Router); ok && router.basePath == last.basePath {
+				router.name = name
+			}
+		}
+	}
+	root.names[name] = last
+}
+
+// Building the URL of the named route, the values of the parameters are checked by their types.
+// `app.URLFor("user", just.H{"id": 12})`
+func (r *Router) URLFor(name string, params H) (string, error) {
+	root := r.rootRouter()
+	root.mutex.RLock()
+	route, ok := root.names[name]
+	root.mutex.RUnlock()
+	if !ok {
+		return "", ErrRouteNameNotFound
+	}
+	return buildRoutePath(route.segments, params)
+}
+
 // Create group router.
-// The group does not support regular expressions, text only.
 func (r *Router) Group(relativePath string, handlers ...HandlerFunc) IRouter {
 	if len(relativePath) < 1 || relativePath == "/" {
 		panic(fmt.Errorf("the group cannot be empty"))
-		return nil
 	}
-	var (
-		rxPath          *regexp.Regexp
-		routeParamNames []string
-	)
 	basePath := joinPaths(r.basePath, strings.TrimRight(relativePath, "/"))
-	if strings.IndexByte(basePath, '{') >= 0 {
-		rxPath, routeParamNames = regularityBasePath(basePath, false, true)
-	}
+	segments, routeParamNames := parseRoutePath(basePath)
+	root := r.rootRouter()
+	root.mutex.Lock()
+	defer root.mutex.Unlock()
 	group := &Router{
 		basePath:        basePath,
-		rxPath:          rxPath,
+		segments:        segments,
 		handlers:        connectHandlersByRouter(r, handlers),
 		routeParamNames: routeParamNames,
 		parent:          r,
@@ -239,6 +464,41 @@ func (r *Router) Group(relativePath string, handlers ...HandlerFunc) IRouter {
 	return group
 }
 
+// Create group router by the host of the request, the host supports parameters (`{tenant}.example.com`).
+// Parameters of the host are available by Context.Param, requests to other hosts are processed by the default routes.
+func (r *Router) Host(host string, handlers ...HandlerFunc) IRouter {
+	host = strings.TrimSpace(host)
+	if len(host) < 1 {
+		panic(fmt.Errorf("the host cannot be empty"))
+	}
+	hostSegments, hostParamNames := parseHostPattern(host)
+	root := r.rootRouter()
+	root.mutex.Lock()
+	defer root.mutex.Unlock()
+	group := &Router{
+		basePath:        r.basePath,
+		segments:        r.segments,
+		handlers:        connectHandlersByRouter(r, handlers),
+		routeParamNames: append(hostParamNames, r.routeParamNames...),
+		host:            host,
+		hostSegments:    hostSegments,
+		parent:          r,
+		groups:          nil,
+		routes:          nil,
+	}
+	if len(hostParamNames) > 0 {
+		root.hosts = append(root.hosts, group)
+	} else {
+		// Группы хостов без параметров проверяются первыми
+		i := 0
+		for i < len(root.hosts) && !hasPatternParams(root.hosts[i].hostSegments) {
+			i++
+		}
+		root.hosts = append(root.hosts[:i], append([]*Router{group}, root.hosts[i:]...)...)
+	}
+	return group
+}
+
 // Create a HTTP request handler.
 func (r *Router) Handle(httpMethod, relativePath string, handlers ...HandlerFunc) IRoute {
 	if matches, err := regexp.MatchString("^[A-Z]+$", httpMethod); !matches || err != nil {
@@ -251,8 +511,8 @@ func (r *Router) Handle(httpMethod, relativePath string, handlers ...HandlerFunc
 // Internally a http.FileServer is used, therefore http.NotFound is used instead of the Router's NotFound handler.
 // To use the operating system's file system implementation, use:
 // `router.Static("/static", "/var/www")`
-func (r *Router) Static(relativePath, root string) IRoute {
-	return r.StaticFS(relativePath, http.Dir(root))
+func (r *Router) Static(relativePath, root string, options ...StaticOptions) IRoute {
+	return r.StaticFS(relativePath, http.Dir(root), options...)
 }
 
 // StaticFile registers a single route in order to server a single file of the local filesystem.
@@ -264,16 +524,57 @@ func (r *Router) StaticFile(relativePath, filePath string) IRoute {
 	return r.GET(relativePath, handler).HEAD(relativePath, handler)
 }
 
-// StaticFS works just like `Static()` but a custom `http.FileSystem` can be used instead.
-func (r *Router) StaticFS(relativePath string, fs http.FileSystem) IRoute {
-	fileServer := http.StripPrefix(joinPaths(r.basePath, relativePath), http.FileServer(fs))
+// StaticFS works just like `Static()` but a custom `http.FileSystem` can be used instead (embed.FS by http.FS).
+// Options: index fallback of single-page applications, precompressed files, cache headers, directory listing.
+// `router.StaticFS("/", http.Dir("./dist"), just.StaticOptions{Fallback: "index.html", Precompressed: []string{"br", "gzip"}})`
+func (r *Router) StaticFS(relativePath string, fs http.FileSystem, options ...StaticOptions) IRoute {
+	var opts StaticOptions
+	if len(options) > 0 {
+		opts = options[0]
+	}
+	fileServer := newStaticHandler(fs, opts)
 	handler := func(c *Context) IResponse {
-		return StreamResponse(func(w http.ResponseWriter, r *http.Request) {
-			fileServer.ServeHTTP(w, r)
+		p := "/" + c.ParamDef("filepath", "")
+		if rp := c.Request.URL.Path; len(p) > 1 && len(rp) > 1 && rp[len(rp)-1] == '/' {
+			p += "/"
+		}
+		return StreamResponse(func(w http.ResponseWriter, req *http.Request) {
+			fileServer.ServeHTTP(w, stripRequestPrefix(req, p))
+		})
+	}
+	return r.handleMethods([]string{http.MethodGet, http.MethodHead}, path.Join(relativePath, "/{filepath:path}"), []HandlerFunc{handler})
+}
+
+// Mount the standard handler by the prefix for all methods, the prefix is stripped from the path of the request as http.StripPrefix does.
+// Middleware of the groups is executed before the handler.
+// `router.Mount("/legacy", legacyMux)` - the handler gets /users for /legacy/users.
+func (r *Router) Mount(prefix string, handler http.Handler) IRoute {
+	if handler == nil {
+		panic(fmt.Errorf("the mounted handler [%s] is nil", prefix))
+	}
+	mountHandler := func(c *Context) IResponse {
+		rest, _ := c.Param(mountParamName)
+		if len(rest) > 0 {
+			rest = "/" + rest
+		}
+		if p := c.Request.URL.Path; len(p) > 1 && p[len(p)-1] == '/' {
+			rest += "/"
+		}
+		return StreamResponse(func(w http.ResponseWriter, req *http.Request) {
+			handler.ServeHTTP(w, stripRequestPrefix(req, rest))
 		})
 	}
-	urlPattern := path.Join(relativePath, "/{filepath:path}")
-	return r.GET(urlPattern).HEAD(urlPattern, handler)
+	return r.handleMethods([]string{
+		http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodOptions,
+	}, joinPaths(prefix, "/{"+mountParamName+":path}"), []HandlerFunc{mountHandler})
+}
+
+// Mount other application by the prefix (see Mount).
+func (r *Router) MountApp(prefix string, app IApplication) IRoute {
+	if app == nil {
+		panic(fmt.Errorf("the mounted application [%s] is nil", prefix))
+	}
+	return r.Mount(prefix, app)
 }
 
 // POST is a shortcut for router.Handle("POST", path, handlers...).
@@ -313,37 +614,99 @@ func (r *Router) HEAD(relativePath string, handlers ...HandlerFunc) IRoute {
 
 // Any registers a route that matches all the HTTP methods. GET, POST, PUT, PATCH, DELETE.
 func (r *Router) ANY(relativePath string, handlers ...HandlerFunc) IRoute {
-	r.handle(http.MethodGet, relativePath, handlers)
-	r.handle(http.MethodPost, relativePath, handlers)
-	r.handle(http.MethodPut, relativePath, handlers)
-	r.handle(http.MethodPatch, relativePath, handlers)
-	r.handle(http.MethodDelete, relativePath, handlers)
-	return r
+	return r.handleMethods([]string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}, relativePath, handlers)
 }
 
+// Checking the path for compliance with the router (the route checks the whole path, the group checks the prefix).
 func (r *Router) CheckPath(path string) (map[string]string, bool) {
-	if r.rxPath != nil {
-		if r.rxPath.MatchString(path) {
-			if indexes := r.rxPath.FindStringSubmatchIndex(path); len(indexes) > 2 && len(indexes)%2 == 0 {
-				values := make([]string, 0)
-				for e, i := 0, 2; i < len(indexes); i += 2 {
-					if indexes[i] >= e {
-						values = append(values, path[indexes[i]:indexes[i+1]])
-						e = indexes[i+1]
-					}
-				}
-				if len(values) >= len(r.routeParamNames) {
-					params := make(map[string]string)
-					for i := 0; i < len(r.routeParamNames); i++ {
-						params[r.routeParamNames[i]] = values[i]
-					}
-					return params, true
-				}
-			}
-			return nil, false
+	if values, ok := matchPathSegments(r.segments, normalizeRequestPath(path), nil, r.exactly); ok {
+		return pathParamsFromValues(values), true
+	}
+	return nil, false
+}
+
+// Type of the route parameter by its name (parameters of the host groups are taken into account).
+func (r *Router) paramType(name string) (string, bool) {
+	if t, ok := segmentsParamType(r.segments, name); ok {
+		return t, true
+	}
+	for g := r.parent; g != nil; g = g.parent {
+		if t, ok := segmentsParamType(g.hostSegments, name); ok {
+			return t, true
 		}
 	}
-	return nil, strings.Compare(path, r.basePath) == 0
+	return "", false
+}
+
+func (r *Router) Name() string {
+	return r.name
+}
+
+// Add metadata to the last registered routes, the group without routes adds metadata to all its routes.
+// `app.GET("/admin/users", handler).SetMetadata(just.H{"scopes": []string{"users:read"}})`
+func (r *Router) SetMetadata(metadata H) IRoute {
+	r.setMetadata(nil, metadata)
+	return r
+}
+
+func (r *Router) setMetadata(targets []*Router, metadata H) {
+	root := r.rootRouter()
+	root.mutex.Lock()
+	defer root.mutex.Unlock()
+	if targets == nil {
+		targets = r.lastRoutes
+	}
+	if len(targets) < 1 {
+		targets = []*Router{r}
+	}
+	for _, target := range targets {
+		if target.metadata == nil {
+			target.metadata = make(H, len(metadata))
+		}
+		for key, value := range metadata {
+			target.metadata[key] = value
+		}
+	}
+}
+
+// Metadata of the route with the metadata of its groups (values of the route replace values of the groups).
+func (r *Router) Metadata() H {
+	root := r.rootRouter()
+	root.mutex.RLock()
+	defer root.mutex.RUnlock()
+	return r.mergedMetadata()
+}
+
+func (r *Router) mergedMetadata() H {
+	var chain []*Router
+	for g := r; g != nil; g = g.parent {
+		if len(g.metadata) > 0 {
+			chain = append(chain, g)
+		}
+	}
+	if len(chain) < 1 {
+		return nil
+	}
+	result := make(H)
+	for i := len(chain) - 1; i >= 0; i-- {
+		for key, value := range chain[i].metadata {
+			result[key] = value
+		}
+	}
+	return result
+}
+
+// Value of the metadata of the route or its groups.
+func (r *Router) MetadataValue(key string) (interface{}, bool) {
+	root := r.rootRouter()
+	root.mutex.RLock()
+	defer root.mutex.RUnlock()
+	for g := r; g != nil; g = g.parent {
+		if value, ok := g.metadata[key]; ok {
+			return value, true
+		}
+	}
+	return nil, false
 }
 
 func (r *Router) BasePath() string {
@@ -1,6 +1,12 @@
 package just
 
 import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -18,3 +24,589 @@ func TestRouterParamsInGroup(t *testing.T) {
 	}
 
 }
+
+func TestRouterTree(t *testing.T) {
+	app := New()
+	handler := func(c *Context) IResponse {
+		return &Response{Status: 200, Bytes: []byte(c.RouteBasePath())}
+	}
+	app.GET("/", handler)
+	app.GET("/users", handler)
+	app.GET("/users/{id:integer}", handler)
+	app.GET("/users/{name}", handler)
+	app.GET("/users/{id:integer}/{mode:enum(full,short)}", handler)
+	app.GET("/files/{name}{ext:file.ext}", handler)
+	app.GET("/static/{filepath:path}", handler)
+	app.POST("/users", handler)
+	group := app.Group("/api/{version:regexp(v\\d+)}")
+	group.GET("/items/{uuid:uuid}", handler)
+
+	tests := []struct {
+		method, path, route string
+		params              map[string]string
+	}{
+		{"GET", "/", "/", nil},
+		{"GET", "/users", "/users", nil},
+		{"GET", "/users/", "/users", nil},
+		{"GET", "/users/12", "/users/{id:integer}", map[string]string{"id": "12"}},
+		{"GET", "/users/john", "/users/{name}", map[string]string{"name": "john"}},
+		{"GET", "/users/12/full", "/users/{id:integer}/{mode:enum(full,short)}", map[string]string{"id": "12", "mode": "full"}},
+		{"GET", "/files/report.pdf", "/files/{name}{ext:file.ext}", map[string]string{"name": "report", "ext": ".pdf"}},
+		{"GET", "/static/js/app.js", "/static/{filepath:path}", map[string]string{"filepath": "js/app.js"}},
+		{"GET", "/api/v2/items/00002a37-0000-1000-8000-00805f9b34fb", "/api/{version:regexp(v\\d+)}/items/{uuid:uuid}", map[string]string{
+			"version": "v2", "uuid": "00002a37-0000-1000-8000-00805f9b34fb",
+		}},
+		{"POST", "/users", "/users", nil},
+		{"GET", "/users/12/none", "", nil},
+		{"GET", "/api/x2/items/00002a37-0000-1000-8000-00805f9b34fb", "", nil},
+		{"DELETE", "/users", "", nil},
+	}
+	for _, test := range tests {
+		route, params := app.(*application).findRoute(httptest.NewRequest(test.method, test.path, nil))
+		if len(test.route) < 1 {
+			if route != nil {
+				t.Errorf("%s %s: unexpected route %s", test.method, test.path, route.BasePath())
+			}
+			continue
+		}
+		if route == nil {
+			t.Errorf("%s %s: route not found", test.method, test.path)
+			continue
+		}
+		if route.BasePath() != test.route {
+			t.Errorf("%s %s: expected route %s, got %s", test.method, test.path, test.route, route.BasePath())
+		}
+		if len(params) != len(test.params) {
+			t.Errorf("%s %s: expected params %v, got %v", test.method, test.path, test.params, params)
+			continue
+		}
+		for key, value := range test.params {
+			if params[key] != value {
+				t.Errorf("%s %s: expected param %s=%s, got %s", test.method, test.path, key, value, params[key])
+			}
+		}
+	}
+}
+
+func TestOptionalAndCatchAllParams(t *testing.T) {
+	app := New()
+	handler := func(c *Context) IResponse {
+		var result []string
+		for _, name := range []string{"year", "month", "rest", "lang"} {
+			if value, ok := c.Param(name); ok {
+				result = append(result, name+"="+value)
+			}
+		}
+		return &Response{Status: 200, Bytes: []byte(strings.Join(result, ","))}
+	}
+	app.GET("/archive/{year:int}/{month:int?}", handler).SetName("archive")
+	app.GET("/files/{rest...}", handler)
+	app.GET("/docs/{lang?}/intro", handler)
+
+	tests := []struct {
+		path   string
+		status int
+		body   string
+	}{
+		{"/archive/2020", 200, "year=2020"},
+		{"/archive/2020/05", 200, "year=2020,month=05"},
+		{"/archive/2020/may", 404, ""},
+		{"/files/a/b/c.txt", 200, "rest=a/b/c.txt"},
+		{"/docs/intro", 200, ""},
+		{"/docs/en/intro", 200, "lang=en"},
+	}
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest("GET", test.path, nil))
+		if w.Code != test.status || (test.status == 200 && w.Body.String() != test.body) {
+			t.Errorf("%s: expected %d %q, got %d %q", test.path, test.status, test.body, w.Code, w.Body.String())
+		}
+	}
+	if url, err := app.URLFor("archive", H{"year": 2020}); err != nil || url != "/archive/2020" {
+		t.Errorf("unexpected url %q (%v)", url, err)
+	}
+	if url, err := app.URLFor("archive", H{"year": 2020, "month": 5}); err != nil || url != "/archive/2020/5" {
+		t.Errorf("unexpected url %q (%v)", url, err)
+	}
+	if routes := app.Routes(); !routes[0].Params[1].Optional || routes[1].Params[0].Type != "path" {
+		t.Errorf("unexpected params %+v", routes)
+	}
+	defer func() {
+		if rvr := recover(); rvr == nil {
+			t.Error("expected panic on the optional param inside the segment")
+		}
+	}()
+	app.GET("/reports/{name?}.json", handler)
+}
+
+func TestGroupMatching(t *testing.T) {
+	app := New()
+	handler := func(c *Context) IResponse {
+		return &Response{Status: 200, Bytes: []byte(c.RouteBasePath())}
+	}
+	app.Group("/admin").GET("/users", handler)
+	api := app.Group("/api/{version}")
+	api.GET("/items", handler)
+	app.Group("/api/v1").GET("/users", handler)
+	app.Group("/api/{version:regexp(v\\d+)}").GET("/users/{id:integer}", handler)
+	app.GET("/pages/{name}", handler)
+	app.GET("/pages/{id:integer}", handler)
+
+	tests := []struct {
+		path, route string
+	}{
+		{"/admin/users", "/admin/users"},
+		{"/v1/admin/users", ""},
+		{"/api/v1/users", "/api/v1/users"},
+		{"/api/v1/items", "/api/{version}/items"},
+		{"/api/v1/users/12", "/api/{version:regexp(v\\d+)}/users/{id:integer}"},
+		{"/api/x1/users/12", ""},
+		{"/pages/12", "/pages/{id:integer}"},
+		{"/pages/about", "/pages/{name}"},
+	}
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest("GET", test.path, nil))
+		if len(test.route) < 1 {
+			if w.Code != 404 {
+				t.Errorf("%s: expected status 404, got %d %q", test.path, w.Code, w.Body.String())
+			}
+		} else if w.Code != 200 || w.Body.String() != test.route {
+			t.Errorf("%s: expected route %s, got %d %q", test.path, test.route, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestURLFor(t *testing.T) {
+	app := New()
+	handler := func(c *Context) IResponse { return nil }
+	app.GET("/", handler).SetName("index")
+	app.GET("/users/{id:integer}/{mode:enum(full,short)}", handler).SetName("user")
+	app.Group("/files").GET("/{name}{ext:file.ext}", handler).SetName("file")
+	app.GET("/static/{filepath:path}", handler).SetName("static")
+
+	tests := []struct {
+		name   string
+		params H
+		url    string
+		err    bool
+	}{
+		{"index", nil, "/", false},
+		{"user", H{"id": 12, "mode": "full"}, "/users/12/full", false},
+		{"user", H{"id": "abc", "mode": "full"}, "", true},
+		{"user", H{"id": 12, "mode": "none"}, "", true},
+		{"user", H{"id": 12}, "", true},
+		{"file", H{"name": "my report", "ext": ".pdf"}, "/files/my%20report.pdf", false},
+		{"static", H{"filepath": "js/app.js"}, "/static/js/app.js", false},
+		{"unknown", nil, "", true},
+	}
+	for _, test := range tests {
+		url, err := app.URLFor(test.name, test.params)
+		if test.err {
+			if err == nil {
+				t.Errorf("%s %v: expected error, got %s", test.name, test.params, url)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s %v: unexpected error %v", test.name, test.params, err)
+		} else if url != test.url {
+			t.Errorf("%s %v: expected %s, got %s", test.name, test.params, test.url, url)
+		}
+	}
+	if url, err := app.(*application).templateURLFor("user", "id", 1, "mode", "short"); err != nil || url != "/users/1/short" {
+		t.Errorf("template url: unexpected result %s, %v", url, err)
+	}
+
+	// Шаблоны, загруженные до регистрации рендерера и после нее
+	dir, err := ioutil.TempDir("", "just-templates")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	ioutil.WriteFile(filepath.Join(dir, "link.html"), []byte(`{{ url "user" "id" 1 "mode" "short" }}`), 0644)
+	before := &HTMLRenderer{}
+	for name, f := range app.TemplateFuncs() {
+		before.AddFunc(name, f)
+	}
+	if err := before.LoadTemplateGlob("pages", filepath.Join(dir, "*.html")); err != nil {
+		t.Fatal(err)
+	}
+	app.TemplatingManager().SetRenderer("before", before)
+	after := &HTMLRenderer{}
+	app.TemplatingManager().SetRenderer("after", after)
+	if err := after.LoadTemplateGlob("pages", filepath.Join(dir, "*.html")); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"before", "after"} {
+		if b, err := app.TemplatingManager().Renderer(name).Render("link.html", nil); err != nil || string(b) != "/users/1/short" {
+			t.Errorf("%s: unexpected result %q, %v", name, b, err)
+		}
+	}
+}
+
+func TestMethodNotAllowed(t *testing.T) {
+	app := New()
+	handler := func(c *Context) IResponse { return &Response{Status: 200} }
+	app.GET("/users/{id:integer}", handler)
+	app.PUT("/users/{id:integer}", handler)
+	app.POST("/users", handler)
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("DELETE", "/users/12", nil))
+	if w.Code != 405 {
+		t.Errorf("expected status 405, got %d", w.Code)
+	}
+	if allow := w.Header().Get("Allow"); allow != "GET, PUT, OPTIONS" {
+		t.Errorf("unexpected Allow header %q", allow)
+	}
+
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("OPTIONS", "/users", nil))
+	if w.Code != 204 || w.Header().Get("Allow") != "POST, OPTIONS" {
+		t.Errorf("unexpected OPTIONS response %d %q", w.Code, w.Header().Get("Allow"))
+	}
+
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("DELETE", "/none", nil))
+	if w.Code != 404 {
+		t.Errorf("expected status 404, got %d", w.Code)
+	}
+
+	app.SetMethodNotAllowedHandler(func(c *Context) IResponse {
+		return &Response{Status: 405, Bytes: []byte(strings.Join(c.AllowedMethods(), ","))}
+	})
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("PATCH", "/users", nil))
+	if w.Code != 405 || w.Body.String() != "POST,OPTIONS" || w.Header().Get("Allow") != "POST, OPTIONS" {
+		t.Errorf("unexpected custom response %d %q %q", w.Code, w.Body.String(), w.Header().Get("Allow"))
+	}
+}
+
+func TestPathRedirects(t *testing.T) {
+	app := New()
+	handler := func(c *Context) IResponse { return &Response{Status: 200} }
+	app.GET("/users/{id:integer}/Profile", handler)
+	app.POST("/users", handler)
+	app.GET("/files/{file:path}", handler)
+
+	tests := []struct {
+		method, path string
+		status       int
+		location     string
+	}{
+		{"GET", "/users/12/Profile/", 301, "/users/12/Profile"},
+		{"POST", "/users/?x=1", 308, "/users?x=1"},
+		{"GET", "/users//12/./Profile", 301, "/users/12/Profile"},
+		{"GET", "/users/12/../13/Profile", 301, "/users/13/Profile"},
+		{"GET", "/USERS/12/profile", 301, "/users/12/Profile"},
+		{"GET", "/Files/a/B.txt", 301, "/files/a/B.txt"},
+		{"GET", "/users/12/Profile", 200, ""},
+		{"GET", "/users/x/Profile", 404, ""},
+	}
+	app.SetRedirectTrailingSlash(true).SetRedirectFixedPath(true, true)
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest(test.method, test.path, nil))
+		if w.Code != test.status || w.Header().Get("Location") != test.location {
+			t.Errorf("%s %s: expected %d %q, got %d %q", test.method, test.path, test.status, test.location, w.Code, w.Header().Get("Location"))
+		}
+	}
+
+	app.SetRedirectTrailingSlash(false).SetRedirectFixedPath(true, false)
+	for path, status := range map[string]int{"/users/12/Profile/": 200, "/users//12/Profile": 301, "/USERS/12/Profile": 404} {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		if w.Code != status {
+			t.Errorf("GET %s: expected %d, got %d", path, status, w.Code)
+		}
+	}
+}
+
+func TestPathRedirectsSafety(t *testing.T) {
+	dir, err := ioutil.TempDir("", "just-redirects")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	os.MkdirAll(filepath.Join(dir, "sub"), 0755)
+
+	app := New().SetRedirectTrailingSlash(true).SetRedirectFixedPath(true, false)
+	app.GET("/pages/{page}", func(c *Context) IResponse { return &Response{Status: 200} })
+	app.Static("/static", dir)
+	app.StaticFS("/", http.Dir(dir))
+
+	for _, p := range []string{"//evil.com/", "/\\evil.com/", "/pages/..//evil.com/", "/pages/../\\evil.com"} {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest("GET", p, nil))
+		if location := w.Header().Get("Location"); len(location) > 0 && (len(location) < 2 || location[0] != '/' || location[1] == '/' || location[1] == '\\') {
+			t.Errorf("GET %s: unsafe redirect %d %q", p, w.Code, location)
+		}
+	}
+	if location := safeRedirectPath("//\\evil.com"); location != "/evil.com" {
+		t.Errorf("unexpected location %q", location)
+	}
+	// Экранированные символы пути остаются в пути, а не становятся запросом
+	escaped := New().SetRedirectTrailingSlash(true).SetRedirectFixedPath(true, false)
+	escaped.GET("/files/{name}", func(c *Context) IResponse { return &Response{Status: 200} })
+	escaped.GET("/dirs/{a}/{b}", func(c *Context) IResponse { return &Response{Status: 200} })
+	for p, expected := range map[string]string{
+		"/files/a%3Fb/":         "/files/a%3Fb",
+		"/files/a%3Fb/?x=1":     "/files/a%3Fb?x=1",
+		"/pages/../files/a%3Fb": "/files/a%3Fb",
+		"/dirs/a%2Fb/":          "/dirs/a%2Fb",
+		"/files/%D1%84%20%23/":  "/files/%D1%84%20%23",
+	} {
+		w := httptest.NewRecorder()
+		escaped.ServeHTTP(w, httptest.NewRequest("GET", p, nil))
+		if location := w.Header().Get("Location"); w.Code != 301 || location != expected {
+			t.Errorf("GET %s: expected redirect to %q, got %d %q", p, expected, w.Code, location)
+		}
+	}
+	// Каталог без index файла не перенаправляется по кругу
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/static/sub/", nil))
+	if w.Code != 200 {
+		t.Errorf("expected listing of the directory, got %d %q", w.Code, w.Header().Get("Location"))
+	}
+}
+
+func TestMount(t *testing.T) {
+	legacy := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte(req.Method + " " + req.URL.Path + "?" + req.URL.RawQuery))
+	})
+	sub := New()
+	sub.GET("/users/{id:integer}", func(c *Context) IResponse {
+		return &Response{Status: 200, Bytes: []byte("user " + c.MustParam("id"))}
+	})
+
+	app := New()
+	admin := app.Group("/admin", func(c *Context) IResponse {
+		if c.MustRequestHeader("X-Token") != "secret" {
+			return &Response{Status: 403}
+		}
+		return c.Next()
+	})
+	admin.Mount("/legacy", legacy)
+	app.MountApp("/v1", sub)
+
+	tests := []struct {
+		method, path, token string
+		status              int
+		body                string
+	}{
+		{"GET", "/admin/legacy/a/b?x=1", "secret", 200, "GET /a/b?x=1"},
+		{"POST", "/admin/legacy/", "secret", 200, "POST /?"},
+		{"DELETE", "/admin/legacy", "secret", 200, "DELETE ?"},
+		{"GET", "/admin/legacy/a", "", 403, ""},
+		{"GET", "/v1/users/12", "", 200, "user 12"},
+		{"GET", "/v1/none", "", 404, ""},
+	}
+	for _, test := range tests {
+		w, req := httptest.NewRecorder(), httptest.NewRequest(test.method, test.path, nil)
+		req.Header.Set("X-Token", test.token)
+		app.ServeHTTP(w, req)
+		if w.Code != test.status || (len(test.body) > 0 && w.Body.String() != test.body) {
+			t.Errorf("%s %s: expected %d %q, got %d %q", test.method, test.path, test.status, test.body, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestRouteConflicts(t *testing.T) {
+	app := New().SetRouteConflictsAsErrors(true)
+	handler := func(c *Context) IResponse { return nil }
+	app.GET("/users", handler)
+	app.GET("/users/", handler)
+	app.GET("/users/{name}", handler)
+	app.GET("/users/{id:integer}", handler)
+	app.GET("/users/me", handler)
+	app.GET("/items/{id:integer}", handler)
+	app.GET("/items/{code:regexp(\\d{3})}", handler)
+	app.GET("/items/{uuid:uuid}", handler)
+	app.GET("/items/{sort:enum(new,top)}", handler)
+	app.POST("/upload", handler).Match(MatchContentType("application/json"))
+	app.POST("/upload", handler)
+	app.GET("/files/{name}", handler)
+	app.GET("/files/{file}", handler)
+
+	expected := []string{
+		"Route GET /users duplicates /users",
+		"Routes GET /users/{name} and /users/{id:integer} overlap, the route is selected by the type of the parameter",
+		"Routes GET /items/{id:integer} and /items/{code:regexp(\\d{3})} are ambiguous, the selected route depends on the order of registration",
+		"Routes GET /items/{id:integer} and /items/{uuid:uuid} are ambiguous, the selected route depends on the order of registration",
+		"Route GET /files/{file} duplicates /files/{name}",
+	}
+	conflicts := app.RouteConflicts()
+	if len(conflicts) != len(expected) {
+		t.Fatalf("expected %d conflicts, got %v", len(expected), conflicts)
+	}
+	for i, err := range conflicts {
+		if err.Error() != expected[i] {
+			t.Errorf("expected conflict %q, got %q", expected[i], err.Error())
+		}
+	}
+
+	SetDebugMode(true)
+	defer SetDebugMode(false)
+	defer func() {
+		if rvr := recover(); rvr == nil {
+			t.Error("expected panic on the route conflict in debug mode")
+		}
+	}()
+	app = New()
+	app.GET("/users/{name}", handler)
+	app.GET("/users/{login}", handler)
+	app.RouteConflicts()
+}
+
+func TestRouteConflictsAtRegistration(t *testing.T) {
+	SetDebugMode(true)
+	defer SetDebugMode(false)
+	handler := func(c *Context) IResponse { return &Response{Status: 200} }
+	registers := func(fn func()) (conflict interface{}) {
+		defer func() { conflict = recover() }()
+		fn()
+		return nil
+	}
+
+	// Конфликт сообщается вызовом регистрации конфликтующего роута
+	app := New()
+	if err := registers(func() { app.GET("/b", handler) }); err != nil {
+		t.Fatalf("unexpected conflict %v", err)
+	}
+	err := registers(func() { app.GET("/b", handler) })
+	if e, ok := err.(*RouteConflictError); !ok || e.Kind != RouteConflictDuplicate {
+		t.Errorf("expected duplicate at the registration, got %v", err)
+	}
+	if err := registers(func() { app.GET("/unrelated", handler) }); err != nil {
+		t.Errorf("unexpected conflict of other route %v", err)
+	}
+
+	// Матчеры, добавленные после регистрации, проверяются повторно
+	app = New().SetRouteConflictsAsErrors(true)
+	app.POST("/upload", handler).Match(MatchContentType("application/json"))
+	app.POST("/upload", handler)
+	if conflicts := app.RouteConflicts(); len(conflicts) != 0 {
+		t.Errorf("unexpected conflicts %v", conflicts)
+	}
+	app.POST("/upload", handler).Match(MatchContentType("application/json"))
+	if conflicts := app.RouteConflicts(); len(conflicts) != 1 || conflicts[0].Error() != "Route POST /upload duplicates /upload" {
+		t.Errorf("expected duplicate after Match, got %v", conflicts)
+	}
+
+	// Без Run и RouteConflicts первый запрос не вызывает panic
+	app = New()
+	registers(func() {
+		app.GET("/users/{name}", handler)
+		app.GET("/users/{login}", handler)
+	})
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/users/bob", nil))
+	if w.Code != 200 {
+		t.Errorf("expected status 200, got %d", w.Code)
+	}
+}
+
+func TestRoutes(t *testing.T) {
+	app := New()
+	handler := func(c *Context) IResponse { return nil }
+	app.Use(handler)
+	app.GET("/", handler).SetName("index")
+	api := app.Group("/api", handler)
+	api.Group("/users").GET("/{id:integer}", handler, handler).PUT("/{id:integer}/{name}", handler)
+
+	routes := app.Routes()
+	if len(routes) != 3 {
+		t.Fatalf("expected 3 routes, got %d", len(routes))
+	}
+	if r := routes[0]; r.Method != "GET" || r.Path != "/" || r.Name != "index" || r.Handlers != 1 || r.Middleware != 1 || len(r.Groups) != 0 {
+		t.Errorf("unexpected route %+v", r)
+	}
+	r := routes[2]
+	if r.Method != "PUT" || r.Path != "/api/users/{id:integer}/{name}" || r.Handlers != 1 || r.Middleware != 2 {
+		t.Errorf("unexpected route %+v", r)
+	}
+	if len(r.Groups) != 2 || r.Groups[0] != "/api" || r.Groups[1] != "/api/users" {
+		t.Errorf("unexpected groups %v", r.Groups)
+	}
+	if len(r.Params) != 2 || r.Params[0] != (RouteParamDescriptor{Name: "id", Type: "integer"}) || r.Params[1] != (RouteParamDescriptor{Name: "name"}) {
+		t.Errorf("unexpected params %v", r.Params)
+	}
+	if routes := api.Routes(); len(routes) != 2 {
+		t.Errorf("expected 2 routes of the group, got %d", len(routes))
+	}
+}
+
+func TestRouteMetadata(t *testing.T) {
+	app := New()
+	auth := func(c *Context) IResponse {
+		scopes, _ := c.RouteInfo().MetadataValue("scopes")
+		for _, scope := range scopes.([]string) {
+			if scope == c.MustRequestHeader("X-Scope") {
+				return c.Next()
+			}
+		}
+		return &Response{Status: 403}
+	}
+	handler := func(c *Context) IResponse {
+		return &Response{Status: 200, Bytes: []byte(c.RouteInfo().Metadata()["description"].(string))}
+	}
+	admin := app.Group("/admin", auth)
+	admin.SetMetadata(H{"scopes": []string{"admin"}, "description": "admin"})
+	admin.GET("/stats", handler)
+	admin.GET("/users", handler).SetMetadata(H{"scopes": []string{"admin", "users:read"}, "description": "users"})
+
+	tests := []struct {
+		path, scope string
+		status      int
+		body        string
+	}{
+		{"/admin/stats", "admin", 200, "admin"},
+		{"/admin/stats", "users:read", 403, ""},
+		{"/admin/users", "users:read", 200, "users"},
+	}
+	for _, test := range tests {
+		w, req := httptest.NewRecorder(), httptest.NewRequest("GET", test.path, nil)
+		req.Header.Set("X-Scope", test.scope)
+		app.ServeHTTP(w, req)
+		if w.Code != test.status || (len(test.body) > 0 && w.Body.String() != test.body) {
+			t.Errorf("%s %s: expected %d %q, got %d %q", test.path, test.scope, test.status, test.body, w.Code, w.Body.String())
+		}
+	}
+	if routes := app.Routes(); len(routes) != 2 || routes[1].Metadata["description"] != "users" || routes[0].Metadata["description"] != "admin" {
+		t.Errorf("unexpected metadata of routes %+v", routes)
+	}
+}
+
+func TestHostRouting(t *testing.T) {
+	app := New()
+	handler := func(name string) HandlerFunc {
+		return func(c *Context) IResponse {
+			return &Response{Status: 200, Bytes: []byte(name + ":" + c.MustParam("tenant") + ":" + c.MustParam("id"))}
+		}
+	}
+	app.GET("/", handler("default"))
+	app.Host("{tenant}.example.com").GET("/", handler("tenant")).GET("/users/{id:integer}", handler("tenant"))
+	app.Host("api.example.com").GET("/", handler("api"))
+
+	tests := []struct {
+		host, path, body string
+	}{
+		{"example.com", "/", "default::"},
+		{"api.example.com", "/", "api::"},
+		{"acme.example.com:8080", "/", "tenant:acme:"},
+		{"ACME.example.com", "/users/12", "tenant:acme:12"},
+		{"acme.example.org", "/", "default::"},
+	}
+	for _, test := range tests {
+		w, req := httptest.NewRecorder(), httptest.NewRequest("GET", test.path, nil)
+		req.Host = test.host
+		app.ServeHTTP(w, req)
+		if w.Body.String() != test.body {
+			t.Errorf("%s%s: expected %q, got %q", test.host, test.path, test.body, w.Body.String())
+		}
+	}
+	if routes := app.Routes(); len(routes) != 4 || routes[1].Host != "{tenant}.example.com" || len(routes[1].Groups) != 0 {
+		t.Errorf("unexpected routes %+v", routes)
+	}
+}
@@ -27,6 +27,12 @@ type ISerializerManager interface {
 	SetDefaultName(string) ISerializerManager
 	SetSerializer(string, []string, ISerializer) ISerializerManager
 	Serializer(n string, byContent bool) ISerializer
+	Negotiate(accept string) (string, bool) // Name of the serializer by the Accept header (false - no acceptable serializer).
+}
+
+// Serializer that can be disabled for serialization (only deserialization), such serializers are not negotiated.
+type ISerializeChecker interface {
+	CanSerialize() bool
 }
 
 type serializerManager struct {
@@ -34,6 +40,8 @@ type serializerManager struct {
 	nameDefaultSerializer string
 	mapByName             map[string]ISerializer
 	mapByContentType      map[string]ISerializer
+	contentTypesByName    map[string][]string // Content types of the serializers (for the negotiation).
+	order                 []string            // Names of the serializers in order of registration.
 }
 
 func (m *serializerManager) Names() []string {
@@ -69,7 +77,14 @@ func (m *serializerManager) SetSerializer(name string, contentTypes []string, se
 		if m.mapByName == nil {
 			m.mapByName = make(map[string]ISerializer)
 		}
+		if _, ok := m.mapByName[name]; !ok {
+			m.order = append(m.order, name)
+		}
 		m.mapByName[name] = serializer
+		if m.contentTypesByName == nil {
+			m.contentTypesByName = make(map[string][]string)
+		}
+		m.contentTypesByName[name] = contentTypes
 		if m.mapByContentType == nil {
 			m.mapByContentType = make(map[string]ISerializer)
 		}
@@ -96,3 +111,69 @@ func (m *serializerManager) Serializer(n string, byContent bool) ISerializer {
 	}
 	return nil
 }
+
+// Negotiation of the serializer by the Accept header with q-values, wildcards and structured syntax suffixes,
+// among equally acceptable serializers the default one is preferred, then in order of registration.
+// Browsers prefer text/html (text/html, application/xml;q=0.9, */*;q=0.8), if no serializer provides it, the default one is used.
+func (m *serializerManager) Negotiate(accept string) (string, bool) {
+	m.RLock()
+	defer m.RUnlock()
+	ranges := parseAcceptRanges(accept)
+	names := m.order
+	def, _ := m.DefaultName()
+	if len(def) > 0 {
+		names = append([]string{def}, names...)
+	}
+	best, bestQ, defQ, htmlQ := "", 0.0, 0.0, 0.0
+	for _, name := range names {
+		if checker, ok := m.mapByName[name].(ISerializeChecker); ok && !checker.CanSerialize() {
+			continue
+		}
+		for _, contentType := range m.contentTypesByName[name] {
+			mediaType := mediaTypeOf(contentType)
+			q := acceptQuality(ranges, mediaType)
+			if name == def && q > defQ {
+				defQ = q
+			}
+			if mediaType == "text/html" && q > htmlQ {
+				htmlQ = q
+			}
+			if q > bestQ {
+				best, bestQ = name, q
+			}
+		}
+	}
+	if best != def && defQ > 0 && htmlQ == 0 && isBrowserAccept(ranges) {
+		return def, true
+	}
+	return best, bestQ > 0
+}
+
+// The most preferred range of the Accept header is text/html (navigation of the browser).
+func isBrowserAccept(ranges []acceptRange) bool {
+	html, other := 0.0, 0.0
+	for _, r := range ranges {
+		if r.mediaType == "text/html" {
+			if r.q > html {
+				html = r.q
+			}
+		} else if r.q > other {
+			other = r.q
+		}
+	}
+	return html > 0 && html >= other
+}
+
+// Serializer of the request without acceptable serializer,
+// successful responses are replaced by the 406 error, errors are sent as is.
+type notAcceptableSerializer struct {
+	ISerializer
+	err *Error
+}
+
+func (s *notAcceptableSerializer) Response(status int, data interface{}) IResponse {
+	if status >= 400 {
+		return s.ISerializer.Response(status, data)
+	}
+	return s.ISerializer.Response(406, s.err)
+}
@@ -0,0 +1,192 @@
+package just
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Errors
+var (
+	ErrStreamingNotSupported = errors.New("streaming not supported by the response writer")
+	ErrSSEClosed             = errors.New("server-sent events stream closed")
+)
+
+// Interval of the keep-alive comments by default.
+const defaultSSEKeepAlive = 15 * time.Second
+
+// Options of the server-sent events stream.
+type SSEOptions struct {
+	KeepAlive time.Duration // Interval of the keep-alive comments (default 15s), negative - disabled.
+	Retry     time.Duration // Reconnection time sent to the client at the start of the stream, 0 - not sent.
+}
+
+// Server-sent event.
+type SSEvent struct {
+	ID    string        // Event id, the client sends it in the Last-Event-ID header on reconnection.
+	Event string        // Event name, empty - message.
+	Retry time.Duration // Reconnection time, 0 - not sent.
+	Data  interface{}   // Event data, strings and bytes are sent as is, other values are encoded to JSON.
+}
+
+// Writer of the server-sent events, safe for concurrent use.
+type SSEWriter struct {
+	mutex       sync.Mutex
+	writer      http.ResponseWriter
+	flusher     http.Flusher
+	done        <-chan struct{}
+	closed      bool
+	lastEventID string
+}
+
+// Id of the last event received by the client before reconnection (Last-Event-ID header).
+func (s *SSEWriter) LastEventID() string {
+	return s.lastEventID
+}
+
+// Channel closed when the client disconnects.
+func (s *SSEWriter) Done() <-chan struct{} {
+	return s.done
+}
+
+// Event data without line breaks in the fields.
+func sseField(value string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(value)
+}
+
+func (s *SSEWriter) write(text string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if !s.closed {
+		select {
+		case <-s.done:
+			s.closed = true
+		default:
+		}
+	}
+	if s.closed {
+		return ErrSSEClosed
+	}
+	if _, err := s.writer.Write([]byte(text)); err != nil {
+		s.closed = true
+		return err
+	}
+	s.flusher.Flush()
+	return nil
+}
+
+// Send the event to the client.
+func (s *SSEWriter) Send(event SSEvent) error {
+	var data string
+	switch v := event.Data.(type) {
+	case nil:
+	case string:
+		data = v
+	case []byte:
+		data = string(v)
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		data = string(b)
+	}
+	var buf strings.Builder
+	if len(event.ID) > 0 {
+		buf.WriteString("id: " + sseField(event.ID) + "\n")
+	}
+	if len(event.Event) > 0 {
+		buf.WriteString("event: " + sseField(event.Event) + "\n")
+	}
+	if event.Retry > 0 {
+		buf.WriteString("retry: " + strconv.FormatInt(int64(event.Retry/time.Millisecond), 10) + "\n")
+	}
+	// Клиент считает концом строки \r\n, \r и \n
+	for _, line := range strings.Split(strings.NewReplacer("\r\n", "\n", "\r", "\n").Replace(data), "\n") {
+		buf.WriteString("data: " + line + "\n")
+	}
+	buf.WriteString("\n")
+	return s.write(buf.String())
+}
+
+// Send the message event with the data.
+func (s *SSEWriter) Data(data interface{}) error {
+	return s.Send(SSEvent{Data: data})
+}
+
+// Send the named event with the data.
+func (s *SSEWriter) Event(name string, data interface{}) error {
+	return s.Send(SSEvent{Event: name, Data: data})
+}
+
+// Send the comment (ignored by the client).
+func (s *SSEWriter) Comment(text string) error {
+	return s.write(": " + sseField(text) + "\n\n")
+}
+
+func (s *SSEWriter) close() {
+	s.mutex.Lock()
+	s.closed = true
+	s.mutex.Unlock()
+}
+
+// Create a server-sent events response, the stream is open until the handler returns or the client disconnects.
+// `return just.SSEResponse(func(s *just.SSEWriter) { for { select { case <-s.Done(): return; case e := <-events: s.Data(e) } } })`
+func SSEResponse(handler func(s *SSEWriter), options ...SSEOptions) IResponse {
+	var opt SSEOptions
+	if len(options) > 0 {
+		opt = options[0]
+	}
+	if opt.KeepAlive == 0 {
+		opt.KeepAlive = defaultSSEKeepAlive
+	}
+	return StreamResponse(func(w http.ResponseWriter, req *http.Request) {
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, ErrStreamingNotSupported.Error(), http.StatusInternalServerError)
+			return
+		}
+		s := &SSEWriter{
+			writer:      w,
+			flusher:     flusher,
+			done:        req.Context().Done(),
+			lastEventID: req.Header.Get("Last-Event-ID"),
+		}
+		w.Header().Set(ContentTypeHeaderKey, "text/event-stream; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+		if opt.Retry > 0 {
+			s.write(fmt.Sprintf("retry: %d\n\n", int64(opt.Retry/time.Millisecond)))
+		} else {
+			flusher.Flush()
+		}
+		finished := make(chan struct{})
+		defer close(finished)
+		defer s.close()
+		if opt.KeepAlive > 0 {
+			go func() {
+				ticker := time.NewTicker(opt.KeepAlive)
+				defer ticker.Stop()
+				for {
+					select {
+					case <-finished:
+						return
+					case <-s.done:
+						return
+					case <-ticker.C:
+						if s.Comment("keep-alive") != nil {
+							return
+						}
+					}
+				}
+			}()
+		}
+		handler(s)
+	})
+}
@@ -0,0 +1,89 @@
+package just
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type sseTestProfiler struct {
+	written int
+}
+
+func (p *sseTestProfiler) OnStartRequest(*http.Request)            {}
+func (p *sseTestProfiler) OnSelectRoute(*http.Request, IRouteInfo) {}
+func (p *sseTestProfiler) OnWriteResponseData(data []byte)         { p.written += len(data) }
+func (p *sseTestProfiler) OnWriteResponseHeader(int, http.Header)  {}
+func (p *sseTestProfiler) Info(...interface{})                     {}
+func (p *sseTestProfiler) Error(...interface{})                    {}
+func (p *sseTestProfiler) Warning(...interface{})                  {}
+func (p *sseTestProfiler) Debug(...interface{})                    {}
+
+func TestSSEResponse(t *testing.T) {
+	profiler := &sseTestProfiler{}
+	app := New().SetProfiler(profiler)
+	app.GET("/events", func(c *Context) IResponse {
+		return SSEResponse(func(s *SSEWriter) {
+			s.Send(SSEvent{ID: "2", Event: "progress", Data: H{"done": 50}})
+			s.Data("line 1\nline 2")
+			s.Data("a\r\nb\revent: admin\rid: 999")
+			s.Send(SSEvent{ID: s.LastEventID(), Retry: time.Second})
+		}, SSEOptions{KeepAlive: -1, Retry: 3 * time.Second})
+	})
+	req := httptest.NewRequest("GET", "/events", nil)
+	req.Header.Set("Last-Event-ID", "1")
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+	expected := "retry: 3000\n\n" +
+		"id: 2\nevent: progress\ndata: {\"done\":50}\n\n" +
+		"data: line 1\ndata: line 2\n\n" +
+		"data: a\ndata: b\ndata: event: admin\ndata: id: 999\n\n" +
+		"id: 1\nretry: 1000\ndata: \n\n"
+	if w.Body.String() != expected || !w.Flushed || profiler.written != len(expected) {
+		t.Errorf("unexpected stream %q (flushed %v)", w.Body.String(), w.Flushed)
+	}
+	if contentType := w.Header().Get(ContentTypeHeaderKey); !strings.HasPrefix(contentType, "text/event-stream") {
+		t.Errorf("unexpected content type %s", contentType)
+	}
+}
+
+func TestSSEDisconnect(t *testing.T) {
+	app := New()
+	stopped := make(chan error, 1)
+	app.GET("/events", func(c *Context) IResponse {
+		return SSEResponse(func(s *SSEWriter) {
+			s.Data("first")
+			<-s.Done()
+			stopped <- s.Data("second")
+		}, SSEOptions{KeepAlive: 10 * time.Millisecond})
+	})
+	server := httptest.NewServer(app)
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req, _ := http.NewRequest("GET", server.URL+"/events", nil)
+	res, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		t.Fatal(err)
+	}
+	reader := bufio.NewReader(res.Body)
+	for _, expected := range []string{"data: first\n", "\n", ": keep-alive\n"} {
+		if line, err := reader.ReadString('\n'); err != nil || line != expected {
+			t.Fatalf("expected %q, got %q (%v)", expected, line, err)
+		}
+	}
+	cancel()
+	res.Body.Close()
+	select {
+	case err := <-stopped:
+		if err != ErrSSEClosed {
+			t.Errorf("expected closed stream, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Error("the stream is not stopped after the client disconnected")
+	}
+}
@@ -0,0 +1,22 @@
+//go:build go1.16
+// +build go1.16
+
+package just
+
+import (
+	"io/fs"
+	"net/http"
+)
+
+// File system of the directory of embed.FS (fs.FS) for StaticFS.
+// `router.StaticFS("/", just.EmbedFS(assets, "dist"), just.StaticOptions{Fallback: "index.html"})`
+func EmbedFS(fsys fs.FS, dir string) http.FileSystem {
+	if len(dir) > 0 && dir != "." {
+		sub, err := fs.Sub(fsys, dir)
+		if err != nil {
+			panic(err)
+		}
+		fsys = sub
+	}
+	return http.FS(fsys)
+}
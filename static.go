@@ -0,0 +1,153 @@
+package just
+
+import (
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Value of the Cache-Control header for immutable files.
+const immutableCacheControl = "public, max-age=31536000, immutable"
+
+// Options of the static files serving.
+type StaticOptions struct {
+	Index                   string         // Index file of the directories (default index.html).
+	Fallback                string         // File for not found paths (single-page applications - index.html), empty - 404.
+	Precompressed           []string       // Encodings of the precompressed files in order of preference (br, gzip), file.js.br or file.js.gz is served instead of file.js.
+	CacheControl            string         // Cache-Control header of the files.
+	Immutable               *regexp.Regexp // Files with fingerprint in the name (app.3f2a1b.js), served with the immutable Cache-Control.
+	DisableDirectoryListing bool           // Directories without the index file are not listed (404).
+}
+
+// Extensions of the precompressed files by the encoding.
+var precompressedExt = map[string]string{
+	"br":   ".br",
+	"gzip": ".gz",
+}
+
+type staticHandler struct {
+	fs         http.FileSystem
+	options    StaticOptions
+	fileServer http.Handler
+}
+
+func newStaticHandler(fs http.FileSystem, options StaticOptions) *staticHandler {
+	if len(options.Index) < 1 {
+		options.Index = "index.html"
+	}
+	return &staticHandler{fs: fs, options: options, fileServer: http.FileServer(fs)}
+}
+
+// Copy of the request with the path without the prefix (as http.StripPrefix does).
+func stripRequestPrefix(req *http.Request, p string) *http.Request {
+	r := new(http.Request)
+	*r = *req
+	r.URL = new(url.URL)
+	*r.URL = *req.URL
+	r.URL.Path, r.URL.RawPath = p, ""
+	return r
+}
+
+// Open the file, directories are replaced by their index file.
+// Returns the path of the opened file, isDir - the path is a directory without the index file.
+func (h *staticHandler) open(name string) (f http.File, file string, isDir bool, err error) {
+	if f, err = h.fs.Open(name); err != nil {
+		return nil, "", false, err
+	}
+	stat, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, "", false, err
+	}
+	if !stat.IsDir() {
+		return f, name, false, nil
+	}
+	f.Close()
+	file = path.Join(name, h.options.Index)
+	if index, err := h.fs.Open(file); err == nil {
+		if stat, err := index.Stat(); err == nil && !stat.IsDir() {
+			return index, file, false, nil
+		}
+		index.Close()
+	}
+	return nil, "", true, nil
+}
+
+// Precompressed variant of the file accepted by the client (empty encoding - not found).
+func (h *staticHandler) openPrecompressed(req *http.Request, name string) (http.File, string) {
+	value := req.Header.Get("Accept-Encoding")
+	if len(h.options.Precompressed) < 1 || len(strings.TrimSpace(value)) < 1 {
+		return nil, ""
+	}
+	accepted := parseAcceptHeader(value)
+	for _, encoding := range h.options.Precompressed {
+		for _, r := range accepted {
+			if r.mediaType != encoding && r.mediaType != "*" {
+				continue
+			}
+			ext, ok := precompressedExt[encoding]
+			if !ok {
+				ext = "." + encoding
+			}
+			if f, err := h.fs.Open(name + ext); err == nil {
+				if stat, err := f.Stat(); err == nil && !stat.IsDir() {
+					return f, encoding
+				}
+				f.Close()
+			}
+			break
+		}
+	}
+	return nil, ""
+}
+
+// Serving of the file by the path without the prefix of the route.
+func (h *staticHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	f, name, isDir, err := h.open(path.Clean("/" + req.URL.Path))
+	if isDir {
+		if !h.options.DisableDirectoryListing {
+			h.fileServer.ServeHTTP(w, req)
+			return
+		}
+		err = os.ErrNotExist
+	}
+	if err != nil && len(h.options.Fallback) > 0 {
+		f, name, _, err = h.open(path.Clean("/" + h.options.Fallback))
+	}
+	if err != nil || f == nil {
+		http.NotFound(w, req)
+		return
+	}
+	defer f.Close()
+	stat, err := f.Stat()
+	if err != nil {
+		http.NotFound(w, req)
+		return
+	}
+	if h.options.Immutable != nil && h.options.Immutable.MatchString(stat.Name()) {
+		w.Header().Set("Cache-Control", immutableCacheControl)
+	} else if len(h.options.CacheControl) > 0 {
+		w.Header().Set("Cache-Control", h.options.CacheControl)
+	}
+	if len(h.options.Precompressed) > 0 {
+		w.Header().Add("Vary", "Accept-Encoding")
+		if compressed, encoding := h.openPrecompressed(req, name); compressed != nil {
+			defer compressed.Close()
+			if compressedStat, err := compressed.Stat(); err == nil {
+				contentType := mime.TypeByExtension(path.Ext(stat.Name()))
+				if len(contentType) < 1 {
+					contentType = "application/octet-stream"
+				}
+				w.Header().Set(ContentTypeHeaderKey, contentType)
+				w.Header().Set("Content-Encoding", encoding)
+				http.ServeContent(w, req, stat.Name(), compressedStat.ModTime(), compressed)
+				return
+			}
+		}
+	}
+	http.ServeContent(w, req, stat.Name(), stat.ModTime(), f)
+}
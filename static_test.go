@@ -0,0 +1,70 @@
+package just
+
+import (
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+)
+
+func TestStaticOptions(t *testing.T) {
+	root, err := ioutil.TempDir("", "just-static")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	files := map[string]string{
+		"index.html":         "<html>app</html>",
+		"app.3f2a1b9c.js":    "console.log(1)",
+		"app.3f2a1b9c.js.br": "br-data",
+		"app.3f2a1b9c.js.gz": "gz-data",
+		"docs/readme.txt":    "readme",
+	}
+	for name, data := range files {
+		os.MkdirAll(filepath.Join(root, filepath.Dir(name)), 0755)
+		if err := ioutil.WriteFile(filepath.Join(root, name), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	app := New()
+	app.Static("/app", root, StaticOptions{
+		Fallback:                "index.html",
+		Precompressed:           []string{"br", "gzip"},
+		CacheControl:            "no-cache",
+		Immutable:               regexp.MustCompile(`\.[0-9a-f]{8}\.js$`),
+		DisableDirectoryListing: true,
+	})
+	app.Static("/files", root)
+
+	tests := []struct {
+		method, path, encoding string
+		status                 int
+		body, contentEncoding  string
+		cacheControl           string
+	}{
+		{"GET", "/app/", "", 200, "<html>app</html>", "", "no-cache"},
+		{"GET", "/app/users/12", "", 200, "<html>app</html>", "", "no-cache"},
+		{"GET", "/app/app.3f2a1b9c.js", "gzip, br", 200, "br-data", "br", immutableCacheControl},
+		{"GET", "/app/app.3f2a1b9c.js", "gzip", 200, "gz-data", "gzip", immutableCacheControl},
+		{"GET", "/app/app.3f2a1b9c.js", "", 200, "console.log(1)", "", immutableCacheControl},
+		{"HEAD", "/app/docs/readme.txt", "", 200, "", "", "no-cache"},
+		{"GET", "/app/docs", "", 200, "<html>app</html>", "", "no-cache"},
+		{"GET", "/files/docs/readme.txt", "", 200, "readme", "", ""},
+		{"GET", "/files/none.txt", "", 404, "", "", ""},
+	}
+	for _, test := range tests {
+		w, req := httptest.NewRecorder(), httptest.NewRequest(test.method, test.path, nil)
+		if len(test.encoding) > 0 {
+			req.Header.Set("Accept-Encoding", test.encoding)
+		}
+		app.ServeHTTP(w, req)
+		if w.Code != test.status || (len(test.body) > 0 && w.Body.String() != test.body) {
+			t.Errorf("%s %s: expected %d %q, got %d %q", test.method, test.path, test.status, test.body, w.Code, w.Body.String())
+		}
+		if w.Header().Get("Content-Encoding") != test.contentEncoding || w.Header().Get("Cache-Control") != test.cacheControl {
+			t.Errorf("%s %s: unexpected headers %v", test.method, test.path, w.Header())
+		}
+	}
+}
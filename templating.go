@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"errors"
 	"html/template"
+	"path/filepath"
 	"sync"
 )
 
@@ -32,8 +33,11 @@ type ITemplatingManager interface {
 type templatingManager struct {
 	sync.RWMutex
 	renderers map[string]IRenderer
+	funcMap   map[string]interface{} // Functions added to each renderer (url, ...).
 }
 
+// Set the renderer and add the functions of the application (url, ...) to it,
+// templates using them must be loaded after SetRenderer or the functions must be added before (see TemplateFuncs).
 func (t *templatingManager) SetRenderer(name string, r IRenderer) {
 	t.RLock()
 	defer t.RUnlock()
@@ -41,6 +45,11 @@ func (t *templatingManager) SetRenderer(name string, r IRenderer) {
 	if t.renderers == nil {
 		t.renderers = make(map[string]IRenderer)
 	}
+	if r != nil {
+		for funcName, i := range t.funcMap {
+			r.AddFunc(funcName, i)
+		}
+	}
 	t.renderers[name] = r
 }
 
@@ -74,14 +83,16 @@ func (r *HTMLRenderer) DefaultContentType(withCharset bool) string {
 // Load HTML template files.
 func (r *HTMLRenderer) LoadTemplateFiles(fileNames ...string) error {
 	if r.template == nil {
-		t, err := template.ParseFiles(fileNames...)
+		if len(fileNames) < 1 {
+			_, err := template.ParseFiles(fileNames...)
+			return err
+		}
+		// Функции должны быть добавлены до разбора шаблонов
+		t, err := template.New(filepath.Base(fileNames[0])).Funcs(r.funcMap).ParseFiles(fileNames...)
 		if err != nil {
 			return err
 		}
 		r.template = t
-		if r.funcMap != nil {
-			r.template = r.template.Funcs(r.funcMap)
-		}
 		return nil
 	}
 	t, err := r.template.ParseFiles(fileNames...)
@@ -95,14 +106,11 @@ func (r *HTMLRenderer) LoadTemplateFiles(fileNames ...string) error {
 // Load HTML template glob.
 func (r *HTMLRenderer) LoadTemplateGlob(name, pattern string) error {
 	if r.template == nil {
-		t, err := template.New(name).ParseGlob(pattern)
+		t, err := template.New(name).Funcs(r.funcMap).ParseGlob(pattern)
 		if err != nil {
 			return err
 		}
 		r.template = t
-		if r.funcMap != nil {
-			r.template = r.template.Funcs(r.funcMap)
-		}
 		return nil
 	}
 	t, err := r.template.New(name).ParseGlob(pattern)
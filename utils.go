@@ -21,7 +21,8 @@ func topSeekReader(reader io.Reader, nopDetect bool) (int64, error) {
 	if reader != nil {
 		if nopDetect {
 			if val := reflect.ValueOf(reader); val.IsValid() {
-				if val.Type().Name() == "nopCloser" {
+				// Go 1.16+ возвращает nopCloserWriterTo для *bytes.Reader
+				if name := val.Type().Name(); name == "nopCloser" || name == "nopCloserWriterTo" {
 					if val = val.FieldByName("Reader"); val.IsValid() {
 						return topSeekReader(val.Interface().(io.Reader), false)
 					}
@@ -34,12 +35,23 @@ func topSeekReader(reader io.Reader, nopDetect bool) (int64, error) {
 			return s.Seek(0, io.SeekStart)
 		} else if f, ok := reader.(*os.File); ok {
 			return f.Seek(0, io.SeekStart)
+		} else if f, ok := reader.(*tempFileBody); ok {
+			return f.Seek(0, io.SeekStart)
 		}
 		return -1, ErrUnsupportedReader
 	}
 	return -1, ErrEmptyReader
 }
 
+func hasString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
 func joinPaths(a string, b string) string {
 	if len(b) < 1 {
 		return a
@@ -9,7 +9,8 @@ import (
 )
 
 /**
- * Note: the validator relies on regular expression patterns of routing (bool, int, float, uuid).
+ * Note: the validator relies on regular expression patterns of routing (bool, int, float, uuid)
+ * and the types of route parameters registered by RegisterParamType.
  */
 
 var (
@@ -128,6 +129,11 @@ func validationString(str string, instruction string) error {
 					return errors.New("is not valid by regexp pattern " + value)
 				}
 			}
+		default:
+			// Типы параметров роутов, зарегистрированные пользователем
+			if t, ok := lookupParamType(name); ok && !t.rx.MatchString(str) {
+				return errors.New("is not " + name)
+			}
 		}
 	}
 	return nil
@@ -182,3 +188,29 @@ func Validation(obj interface{}) []error {
 	}
 	return result
 }
+
+// Name of the field of the structure in the request by the tags (the tag of the serializer first, then json, form, xml and the binding sources).
+// Returns the name and the tag (empty tag - the Go name of the field).
+func requestFieldName(t reflect.Type, goName, serializerTag string) (string, string) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return goName, ""
+	}
+	field, ok := t.FieldByName(goName)
+	if !ok {
+		return goName, ""
+	}
+	for _, tag := range []string{serializerTag, "json", "form", "xml", BindSourcePath, BindSourceQuery, BindSourceHeader, BindSourceCookie} {
+		if len(tag) < 1 {
+			continue
+		}
+		if value, ok := field.Tag.Lookup(tag); ok {
+			if name := strings.TrimSpace(strings.Split(value, ",")[0]); len(name) > 0 && name != "-" {
+				return name, tag
+			}
+		}
+	}
+	return goName, ""
+}
@@ -1,7 +1,10 @@
 package just
 
 import (
+	"encoding/json"
 	"fmt"
+	"net/http/httptest"
+	"strings"
 	"testing"
 )
 
@@ -30,3 +33,64 @@ func TestValidation(t *testing.T) {
 		t.Fail()
 	}
 }
+
+type bindValidTest struct {
+	ID    int64  `path:"id" valid:"min(10)"`
+	Page  int    `query:"page" valid:"max(100)"`
+	Name  string `json:"name" xml:"title" valid:"rgx(^.{3,}$)"`
+	Email string `json:"email,omitempty"`
+}
+
+func TestBindValid(t *testing.T) {
+	app := New()
+	app.POST("/users/{id}", func(c *Context) IResponse {
+		var v bindValidTest
+		if res := c.BindValidResponse(&v); res != nil {
+			return res
+		}
+		return &Response{Status: 200}
+	})
+	tests := []struct {
+		path, contentType, body string
+		status                  int
+		causes                  []ErrorCause
+	}{
+		{"/users/12?page=1", "application/json", `{"name":"John"}`, 200, nil},
+		{"/users/1?page=500", "application/json", `{"name":"Jo"}`, 422, []ErrorCause{
+			{Target: "id", Path: "path"}, {Target: "page", Path: "query"}, {Target: "name", Path: "body"},
+		}},
+		{"/users/12", "application/xml", `<bindValidTest><title>Jo</title></bindValidTest>`, 422, []ErrorCause{
+			{Target: "title", Path: "body"},
+		}},
+		{"/users/x?page=y", "application/json", `{"name":"John"}`, 400, []ErrorCause{
+			{Target: "id", Path: "path"}, {Target: "page", Path: "query"},
+		}},
+		{"/users/12", "application/json", `{"name":`, 400, []ErrorCause{{Path: "body"}}},
+	}
+	for _, test := range tests {
+		req := httptest.NewRequest("POST", test.path, strings.NewReader(test.body))
+		req.Header.Set(ContentTypeHeaderKey, test.contentType)
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+		if w.Code != test.status {
+			t.Errorf("%s: expected %d, got %d %s", test.path, test.status, w.Code, w.Body.String())
+			continue
+		}
+		if test.causes == nil {
+			continue
+		}
+		var e Error
+		if err := json.Unmarshal(w.Body.Bytes(), &e); err != nil {
+			t.Fatal(err)
+		}
+		if len(e.Causes) != len(test.causes) {
+			t.Errorf("%s: unexpected causes %+v", test.path, e.Causes)
+			continue
+		}
+		for i, cause := range test.causes {
+			if e.Causes[i].Target != cause.Target || e.Causes[i].Path != cause.Path || len(e.Causes[i].Description) < 1 {
+				t.Errorf("%s: expected cause %+v, got %+v", test.path, cause, e.Causes[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,242 @@
+package just
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Name of the route parameter with the version in the URL prefix (/api/v2/users).
+const versionParamName = "_version"
+
+var (
+	rxVersionName = regexp.MustCompile(`^\d+(\.\d+)*$`)
+)
+
+// Options of the API versioning.
+type VersioningOptions struct {
+	Vendor string // Vendor of the media type in the Accept header (application/vnd.{vendor}.v2+json), empty - not used.
+	Header string // Header with the version (X-Api-Version: 2), empty - not used.
+}
+
+// Versioned router interface.
+type IVersionedRouter interface {
+	IRouter
+
+	// Create group of the API version ("1", "2", "2.1").
+	Version(string, ...HandlerFunc) IRouter
+	// Create group of the retired API version, responses get Deprecation and Sunset (if set) headers.
+	DeprecatedVersion(string, time.Time, ...HandlerFunc) IRouter
+}
+
+type apiVersion struct {
+	name       string
+	deprecated bool
+	sunset     time.Time
+}
+
+type versionedRouter struct {
+	*Router
+	options  VersioningOptions
+	rxVendor *regexp.Regexp
+	versions []*apiVersion                        // Versions in ascending order.
+	routes   map[string]map[*apiVersion][]*Router // Routes of the versions (map[httpMethod + relativePath]).
+}
+
+// Comparison of versions by numeric parts ("1.10" > "1.9").
+func compareVersions(a, b string) int {
+	partsA, partsB := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(partsA) || i < len(partsB); i++ {
+		var x, y int
+		if i < len(partsA) {
+			x, _ = strconv.Atoi(partsA[i])
+		}
+		if i < len(partsB) {
+			y, _ = strconv.Atoi(partsB[i])
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+func normalizeVersionName(name string) string {
+	return strings.TrimLeft(strings.TrimSpace(name), "vV")
+}
+
+// Create versioned group router, routes of the versions are available by the URL prefix (/api/v2/users)
+// and without it (/api/users) by the version from the Accept vendor type or the header, by default the newest version is used.
+// If the route is not in the requested version, the newest previous version with the route is used.
+func (r *Router) Versioned(relativePath string, options VersioningOptions, handlers ...HandlerFunc) IVersionedRouter {
+	v := &versionedRouter{
+		Router:  r.Group(relativePath, handlers...).(*Router),
+		options: options,
+		routes:  make(map[string]map[*apiVersion][]*Router),
+	}
+	if vendor := strings.TrimSpace(options.Vendor); len(vendor) > 0 {
+		v.rxVendor = regexp.MustCompile(`^application/vnd\.` + regexp.QuoteMeta(strings.ToLower(vendor)) + `\.v(\d+(?:\.\d+)*)(\+[a-z0-9.\-]+)?$`)
+	}
+	return v
+}
+
+func (v *versionedRouter) addVersion(version *apiVersion, handlers []HandlerFunc) IRouter {
+	root := v.rootRouter()
+	root.mutex.Lock()
+	defer root.mutex.Unlock()
+	if !rxVersionName.MatchString(version.name) {
+		panic(fmt.Errorf("the API version [%s] not valid", version.name))
+	}
+	for _, exist := range v.versions {
+		if compareVersions(exist.name, version.name) == 0 {
+			panic(fmt.Errorf("the API version [%s] already exists", version.name))
+		}
+	}
+	v.versions = append(v.versions, version)
+	sort.Slice(v.versions, func(i, j int) bool {
+		return compareVersions(v.versions[i].name, v.versions[j].name) < 0
+	})
+	if version.deprecated {
+		handlers = append([]HandlerFunc{deprecationMiddleware(version)}, handlers...)
+	}
+	return &Router{
+		basePath:        v.basePath,
+		segments:        v.segments,
+		handlers:        connectHandlersByRouter(v.Router, handlers),
+		routeParamNames: v.routeParamNames,
+		version:         version,
+		versioning:      v,
+		parent:          v.Router,
+		groups:          nil,
+		routes:          nil,
+	}
+}
+
+func (v *versionedRouter) Version(name string, handlers ...HandlerFunc) IRouter {
+	return v.addVersion(&apiVersion{name: normalizeVersionName(name)}, handlers)
+}
+
+func (v *versionedRouter) DeprecatedVersion(name string, sunset time.Time, handlers ...HandlerFunc) IRouter {
+	return v.addVersion(&apiVersion{name: normalizeVersionName(name), deprecated: true, sunset: sunset}, handlers)
+}
+
+// Adding the route of the version, the route is processed by the dispatcher of the versioned group.
+func (v *versionedRouter) addRoute(version *apiVersion, route *Router) {
+	relativePath := strings.TrimPrefix(route.basePath, v.basePath)
+	key := route.method + " " + relativePath
+	if _, ok := v.routes[key]; !ok {
+		v.routes[key] = make(map[*apiVersion][]*Router)
+		// Диспетчеры версий: без префикса версии и с префиксом (/v2)
+		dispatcher := []HandlerFunc{v.dispatcher(key)}
+		for _, p := range []string{relativePath, "/{" + versionParamName + ":regexp(v\\d+(?:\\.\\d+)*)}" + relativePath} {
+			basePath := joinPaths(v.basePath, p)
+			segments, routeParamNames := parseRoutePath(basePath)
+			v.treeRouter().insertRoute(&Router{
+				method:          route.method,
+				basePath:        basePath,
+				segments:        segments,
+				handlers:        dispatcher,
+				routeParamNames: routeParamNames,
+				exactly:         true,
+				parent:          v.Router,
+				dispatcher:      true,
+			})
+		}
+	}
+	v.routes[key][version] = append(v.routes[key][version], route)
+}
+
+// Version requested by the header or the vendor type of the Accept header (empty - not requested).
+func (v *versionedRouter) requestedVersion(req *http.Request) string {
+	if len(v.options.Header) > 0 {
+		if value := req.Header.Get(v.options.Header); len(value) > 0 {
+			return normalizeVersionName(value)
+		}
+	}
+	if v.rxVendor != nil {
+		for _, r := range parseAcceptHeader(req.Header.Get("Accept")) {
+			if m := v.rxVendor.FindStringSubmatch(r.mediaType); len(m) > 1 {
+				return m[1]
+			}
+		}
+	}
+	return ""
+}
+
+// Selection of the route of the newest version not greater than the requested one.
+func (v *versionedRouter) selectRoute(key, requested string, req *http.Request) (*apiVersion, *Router) {
+	routes := v.routes[key]
+	for i := len(v.versions) - 1; i >= 0; i-- {
+		version := v.versions[i]
+		if len(requested) > 0 && compareVersions(version.name, requested) > 0 {
+			continue
+		}
+		for _, route := range routes[version] {
+			if route.matchRequest(req) {
+				return version, route
+			}
+		}
+	}
+	return nil, nil
+}
+
+func (v *versionedRouter) dispatcher(key string) HandlerFunc {
+	return func(c *Context) IResponse {
+		params := c.routeParams
+		requested, ok := params[versionParamName]
+		if ok {
+			requested = normalizeVersionName(requested)
+			delete(params, versionParamName)
+		} else {
+			requested = v.requestedVersion(c.Request)
+		}
+		root := v.rootRouter()
+		root.mutex.RLock()
+		version, route := v.selectRoute(key, requested, c.Request)
+		root.mutex.RUnlock()
+		if route == nil {
+			return noRouteResponse(c)
+		}
+		c.apiVersion = version.name
+		// Тело запроса читается с параметрами выбранного роута версии
+		if app, ok := c.app.(*application); ok {
+			if res := app.prepareRequestBody(c, route); res != nil {
+				return res
+			}
+		}
+		return c.resetRoute(route, params).Next()
+	}
+}
+
+// Middleware of the retired version, adds Deprecation and Sunset headers to the response.
+func deprecationMiddleware(version *apiVersion) HandlerFunc {
+	return func(c *Context) IResponse {
+		res := c.Next()
+		if res != nil {
+			if headers := res.GetHeaders(); headers != nil {
+				headers["Deprecation"] = "true"
+				if !version.sunset.IsZero() {
+					headers["Sunset"] = version.sunset.UTC().Format(http.TimeFormat)
+				}
+			}
+		}
+		return res
+	}
+}
+
+// Version of the API group of the route.
+func (r *Router) versionRouter() *Router {
+	for ; r != nil; r = r.parent {
+		if r.version != nil {
+			return r
+		}
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package just
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestVersionedGroup(t *testing.T) {
+	app := New()
+	handler := func(name string) HandlerFunc {
+		return func(c *Context) IResponse {
+			return &Response{Status: 200, Bytes: []byte(name + ":" + c.APIVersion() + ":" + c.ParamDef("id", ""))}
+		}
+	}
+	api := app.Versioned("/api", VersioningOptions{Vendor: "company", Header: "X-Api-Version"})
+	v1 := api.DeprecatedVersion("1", time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC))
+	v1.GET("/users/{id:int}", handler("user"))
+	v1.GET("/orders", handler("orders"))
+	v2 := api.Version("v2")
+	v2.GET("/users/{id:int}", handler("user"))
+
+	tests := []struct {
+		path       string
+		headers    map[string]string
+		status     int
+		body       string
+		deprecated bool
+	}{
+		{"/api/users/1", nil, 200, "user:2:1", false},
+		{"/api/v1/users/1", nil, 200, "user:1:1", true},
+		{"/api/v2/users/1", nil, 200, "user:2:1", false},
+		{"/api/v3/users/1", nil, 200, "user:2:1", false},
+		{"/api/v2/orders", nil, 200, "orders:1:", true},
+		{"/api/users/1", map[string]string{"Accept": "application/vnd.company.v1+json"}, 200, "user:1:1", true},
+		{"/api/users/1", map[string]string{"X-Api-Version": "1"}, 200, "user:1:1", true},
+		{"/api/v0/users/1", nil, 404, "", false},
+		{"/api/users/x", nil, 404, "", false},
+	}
+	for _, test := range tests {
+		w, req := httptest.NewRecorder(), httptest.NewRequest("GET", test.path, nil)
+		for key, value := range test.headers {
+			req.Header.Set(key, value)
+		}
+		app.ServeHTTP(w, req)
+		if w.Code != test.status || (len(test.body) > 0 && w.Body.String() != test.body) {
+			t.Errorf("%s %v: expected %d %q, got %d %q", test.path, test.headers, test.status, test.body, w.Code, w.Body.String())
+		}
+		if deprecated := w.Header().Get("Deprecation") == "true"; deprecated != test.deprecated {
+			t.Errorf("%s %v: expected deprecation %v", test.path, test.headers, test.deprecated)
+		} else if deprecated && w.Header().Get("Sunset") != "Tue, 01 Jan 2030 00:00:00 GMT" {
+			t.Errorf("%s: invalid sunset header %q", test.path, w.Header().Get("Sunset"))
+		}
+	}
+	if routes := app.Routes(); len(routes) != 3 || routes[0].Version != "1" || routes[2].Version != "2" {
+		t.Errorf("invalid versioned routes: %+v", routes)
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	if compareVersions("1.10", "1.9") != 1 || compareVersions("2", "2.0") != 0 || compareVersions("1", "2") != -1 {
+		t.Error("invalid comparison of versions")
+	}
+}